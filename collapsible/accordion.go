@@ -0,0 +1,100 @@
+package collapsible
+
+import (
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// Accordion is a [vxfw.Widget] which lays out a group of [Collapsible] sections in a
+// [vxlayout.Column]. Unless Multiple is set, expanding a section collapses the others.
+type Accordion struct {
+	// Multiple allows more than one section to be expanded at a time.
+	Multiple bool
+	// Options are passed to the Column the sections are laid out in.
+	Options vxlayout.Options
+
+	// OnChange is called when the user expands or collapses a section, and for each section
+	// collapsed because the user expanded another one.
+	OnChange func(index int, expanded bool) (vxfw.Command, error)
+
+	sections []*Collapsible
+}
+
+// NewAccordion returns an [Accordion] containing sections.
+func NewAccordion(sections ...*Collapsible) *Accordion {
+	a := &Accordion{}
+	for _, s := range sections {
+		a.Add(s)
+	}
+	return a
+}
+
+// Add appends section to the accordion.
+func (a *Accordion) Add(section *Collapsible) {
+	section.group = a
+	a.sections = append(a.sections, section)
+	if section.expanded {
+		a.collapseOthers(section)
+	}
+}
+
+// Len returns the number of sections.
+func (a *Accordion) Len() int { return len(a.sections) }
+
+// Section returns the section at index i.
+func (a *Accordion) Section(i int) *Collapsible { return a.sections[i] }
+
+// Index returns the index of section, or -1 if it's not in a.
+func (a *Accordion) Index(section *Collapsible) int {
+	for i, s := range a.sections {
+		if s == section {
+			return i
+		}
+	}
+	return -1
+}
+
+// Expanded returns the indexes of the expanded sections.
+func (a *Accordion) Expanded() []int {
+	var out []int
+	for i, s := range a.sections {
+		if s.expanded {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// SetExpanded expands or collapses the section at index i.
+func (a *Accordion) SetExpanded(i int, expanded bool) {
+	if i < 0 || i >= len(a.sections) {
+		return
+	}
+	a.sections[i].SetExpanded(expanded)
+}
+
+// collapseOthers collapses the sections other than section, unless a allows more than one to be
+// expanded, and returns the ones which were expanded.
+func (a *Accordion) collapseOthers(section *Collapsible) []*Collapsible {
+	if a.Multiple {
+		return nil
+	}
+	var collapsed []*Collapsible
+	for _, s := range a.sections {
+		if s != section && s.expanded {
+			s.expanded = false
+			collapsed = append(collapsed, s)
+		}
+	}
+	return collapsed
+}
+
+var _ vxfw.Widget = &Accordion{}
+
+func (a *Accordion) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	children := make([]vxfw.Widget, len(a.sections))
+	for i, s := range a.sections {
+		children[i] = s
+	}
+	return vxlayout.Column(children, a.Options).Draw(ctx)
+}
@@ -0,0 +1,226 @@
+// Package collapsible provides a [Collapsible] section which shows or hides its body, and an
+// [Accordion] which groups sections together.
+package collapsible
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// Collapsible is a [vxfw.Widget] which draws a header and, when expanded, a body below it.
+// Clicking the header, or pressing Enter or Space while the Collapsible has focus, toggles the
+// body.
+// The height of a Collapsible is the height of its header plus the height of its body when
+// expanded, so it can be placed in a Column without being wrapped in a flexible widget.
+type Collapsible struct {
+	Header vxfw.Widget
+	Body   vxfw.Widget
+
+	// Icons drawn in front of the header for each state
+	ExpandedIcon  string
+	CollapsedIcon string
+	// Style is used for the header row. FocusStyle replaces it while the Collapsible has focus.
+	Style      vaxis.Style
+	FocusStyle vaxis.Style
+
+	// OnToggle is called when the user expands or collapses the section, including when it's
+	// collapsed because the user expanded another section of its [Accordion]. It's not called for
+	// changes made with [Collapsible.SetExpanded].
+	OnToggle func(expanded bool) (vxfw.Command, error)
+
+	expanded bool
+	focused  bool
+	header   *headerRow
+	group    *Accordion
+}
+
+// New returns a collapsed [Collapsible].
+func New(header, body vxfw.Widget) *Collapsible {
+	c := &Collapsible{
+		Header:        header,
+		Body:          body,
+		ExpandedIcon:  "▾ ",
+		CollapsedIcon: "▸ ",
+		FocusStyle:    vaxis.Style{Attribute: vaxis.AttrReverse},
+	}
+	c.header = &headerRow{c: c}
+	return c
+}
+
+// Expanded reports whether the body is shown.
+func (c *Collapsible) Expanded() bool { return c.expanded }
+
+// SetExpanded shows or hides the body. If c is part of an [Accordion] which only allows one
+// expanded section, the other sections are collapsed.
+func (c *Collapsible) SetExpanded(expanded bool) {
+	c.set(expanded)
+}
+
+// set shows or hides the body, and returns the other sections of c's group which were collapsed
+// as a result.
+func (c *Collapsible) set(expanded bool) []*Collapsible {
+	c.expanded = expanded
+	if expanded && c.group != nil {
+		return c.group.collapseOthers(c)
+	}
+	return nil
+}
+
+// Toggle expands or collapses the body as if the user clicked the header. The sections of an
+// [Accordion] collapsed as a result are reported too.
+func (c *Collapsible) Toggle() (vxfw.Command, error) {
+	collapsed := c.set(!c.expanded)
+
+	cmds := []vxfw.Command{vxfw.RedrawCmd{}}
+	for _, s := range append([]*Collapsible{c}, collapsed...) {
+		if s.OnToggle != nil {
+			cmd, err := s.OnToggle(s.expanded)
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, cmd)
+		}
+		if s.group != nil && s.group.OnChange != nil {
+			cmd, err := s.group.OnChange(s.group.Index(s), s.expanded)
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds, nil
+}
+
+func (c *Collapsible) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Key:
+		if ph != vxfw.TargetPhase || ev.EventType == vaxis.EventRelease {
+			return nil, nil
+		}
+		if ev.Matches(vaxis.KeyEnter) || ev.Matches(vaxis.KeySpace) {
+			cmd, err := c.Toggle()
+			return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+		}
+	case vaxis.FocusIn:
+		c.focused = true
+		return vxfw.RedrawCmd{}, nil
+	case vaxis.FocusOut:
+		c.focused = false
+		return vxfw.RedrawCmd{}, nil
+	}
+	return nil, nil
+}
+
+var _ vxfw.Widget = &Collapsible{}
+
+func (c *Collapsible) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	header, err := c.header.Draw(ctx.WithMin(vxfw.Size{}))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	size := vxfw.Size{Width: header.Size.Width, Height: header.Size.Height}
+
+	var body vxfw.Surface
+	if c.expanded && header.Size.Height < ctx.Max.Height {
+		max := ctx.Max
+		if !max.HasUnboundedHeight() {
+			max.Height -= header.Size.Height
+		}
+		body, err = c.Body.Draw(ctx.WithConstraints(vxfw.Size{Width: ctx.Min.Width}, max))
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+		size.Height += body.Size.Height
+		if body.Size.Width > size.Width {
+			size.Width = body.Size.Width
+		}
+	}
+
+	if size.Width < ctx.Min.Width {
+		size.Width = ctx.Min.Width
+	}
+	if size.Height < ctx.Min.Height {
+		size.Height = ctx.Min.Height
+	}
+
+	s := vxfw.Surface{Size: size, Widget: c}
+	s.AddChild(0, 0, header)
+	if c.expanded {
+		s.AddChild(0, int(header.Size.Height), body)
+	}
+	return s, nil
+}
+
+// headerRow draws the icon and the header widget of a [Collapsible], and toggles it when clicked.
+type headerRow struct {
+	c *Collapsible
+}
+
+func (h *headerRow) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	style := h.c.Style
+	if h.c.focused {
+		style = h.c.FocusStyle
+	}
+
+	icon := h.c.CollapsedIcon
+	if h.c.expanded {
+		icon = h.c.ExpandedIcon
+	}
+
+	var iconWidth uint16
+	chars := ctx.Characters(icon)
+	for _, char := range chars {
+		iconWidth += uint16(char.Width)
+	}
+	if iconWidth > ctx.Max.Width {
+		iconWidth = ctx.Max.Width
+	}
+
+	max := ctx.Max
+	if !max.HasUnboundedWidth() {
+		max.Width -= iconWidth
+	}
+	child, err := h.c.Header.Draw(ctx.WithConstraints(vxfw.Size{}, max))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	height := child.Size.Height
+	if height == 0 {
+		height = 1
+	}
+	width := iconWidth + child.Size.Width
+	if !ctx.Max.HasUnboundedWidth() {
+		// The header takes the full width so the whole row is clickable
+		width = ctx.Max.Width
+	}
+
+	s := vxfw.NewSurface(width, height, h)
+	s.FillStyle(style)
+	var col uint16
+	for _, char := range chars {
+		if col+uint16(char.Width) > iconWidth {
+			break
+		}
+		s.WriteCell(col, 0, vaxis.Cell{Character: char, Style: style})
+		col += uint16(char.Width)
+	}
+	s.AddChild(int(iconWidth), 0, child)
+	return s, nil
+}
+
+func (h *headerRow) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		if ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseLeftButton {
+			cmd, err := h.c.Toggle()
+			return []vxfw.Command{cmd, vxfw.FocusWidgetCmd(h.c), vxfw.ConsumeEventCmd{}}, err
+		}
+	case vxfw.MouseEnter:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeClickable), nil
+	case vxfw.MouseLeave:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault), nil
+	}
+	return nil, nil
+}
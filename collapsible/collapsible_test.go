@@ -0,0 +1,104 @@
+package collapsible
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+)
+
+func TestCollapsibleDraw(t *testing.T) {
+	c := New(text.New("header"), text.New("one\ntwo"))
+	ctx := vxfw.DrawContext{
+		Max:        vxfw.Size{Width: 10, Height: math.MaxUint16},
+		Characters: vaxis.Characters,
+	}
+
+	for _, want := range []uint16{1, 3} {
+		surface, err := c.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if surface.Size.Height != want {
+			t.Logf("wrong height with expanded=%v, got=%d, want=%d", c.Expanded(), surface.Size.Height, want)
+			t.Fail()
+		}
+		if _, err := c.Toggle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAccordion(t *testing.T) {
+	var changes []int
+	a := NewAccordion(
+		New(text.New("a"), text.New("body a")),
+		New(text.New("b"), text.New("body b")),
+		New(text.New("c"), text.New("body c")),
+	)
+	a.OnChange = func(index int, expanded bool) (vxfw.Command, error) {
+		changes = append(changes, index)
+		return nil, nil
+	}
+
+	a.SetExpanded(0, true)
+	if _, err := a.Section(2).Toggle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the last section that was expanded stays expanded
+	if got := a.Expanded(); len(got) != 1 || got[0] != 2 {
+		t.Logf("wrong expanded sections, got=%v, want=[2]", got)
+		t.Fail()
+	}
+	// SetExpanded doesn't report a change, only the toggle and the section it collapsed do
+	if len(changes) != 2 || changes[0] != 2 || changes[1] != 0 {
+		t.Logf("wrong changes, got=%v, want=[2 0]", changes)
+		t.Fail()
+	}
+
+	a.Multiple = true
+	a.SetExpanded(0, true)
+	if got := a.Expanded(); len(got) != 2 {
+		t.Logf("wrong expanded sections, got=%v, want=[0 2]", got)
+		t.Fail()
+	}
+}
+
+func TestAccordionReportsCollapsed(t *testing.T) {
+	a := NewAccordion(
+		New(text.New("a"), text.New("body a")),
+		New(text.New("b"), text.New("body b")),
+	)
+	var changes []string
+	a.OnChange = func(index int, expanded bool) (vxfw.Command, error) {
+		changes = append(changes, fmt.Sprintf("%d %t", index, expanded))
+		return nil, nil
+	}
+	var toggled []bool
+	a.Section(0).OnToggle = func(expanded bool) (vxfw.Command, error) {
+		toggled = append(toggled, expanded)
+		return nil, nil
+	}
+
+	if _, err := a.Section(0).Toggle(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Section(1).Toggle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Expanding b collapses a, which is reported to both the accordion and a
+	want := []string{"0 true", "1 true", "0 false"}
+	if fmt.Sprint(changes) != fmt.Sprint(want) {
+		t.Logf("wrong changes, got=%v, want=%v", changes, want)
+		t.Fail()
+	}
+	if len(toggled) != 2 || !toggled[0] || toggled[1] {
+		t.Logf("wrong toggles of a, got=%v, want=[true false]", toggled)
+		t.Fail()
+	}
+}
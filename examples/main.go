@@ -5,6 +5,9 @@ import (
 	"git.sr.ht/~rockorager/vaxis/log"
 	"git.sr.ht/~rockorager/vaxis/vxfw"
 	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp"
+	"github.com/avidal/vxexp/keymap"
+	"github.com/avidal/vxexp/theme"
 	"github.com/avidal/vxexp/vxlayout"
 )
 
@@ -12,20 +15,30 @@ type App struct {
 	infobar vxfw.Widget
 	screens []vxfw.Widget
 	index   int
+	keys    *keymap.Keymap
+	theme   *theme.Theme
 }
 
+// Filler is the style of the space between widgets. Applications can add roles of their own to
+// the ones a theme defines.
+const Filler theme.Role = "filler"
+
 func (a *App) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
-	switch ev := ev.(type) {
-	case vaxis.Key:
-		if ev.Matches('c', vaxis.ModCtrl) {
-			return vxfw.QuitCmd{}, nil
-		}
-		if ev.Matches('l') || ev.Matches('L') {
-			a.changeScreen()
-			return vxfw.ConsumeAndRedraw(), nil
-		}
-	}
-	return nil, nil
+	return a.keys.Handler()(ev, vxfw.CapturePhase)
+}
+
+func (a *App) bindKeys() {
+	a.keys = keymap.New("Global", nil)
+	a.keys.Define("quit", "quit", func() (vxfw.Command, error) {
+		return vxfw.QuitCmd{}, nil
+	})
+	a.keys.Define("layout", "switch layouts", func() (vxfw.Command, error) {
+		a.changeScreen()
+		return vxfw.RedrawCmd{}, nil
+	})
+	a.keys.MustBind("ctrl+c", "quit")
+	a.keys.MustBind("L", "layout")
+	a.keys.MustBind("l", "layout")
 }
 
 func (a *App) changeScreen() {
@@ -37,20 +50,11 @@ func (a *App) changeScreen() {
 }
 
 func makeScreen1() vxfw.Widget {
-	filler := vaxis.Cell{
-		Character: vaxis.Character{
-			Grapheme: "·", Width: 1,
-		},
-		Style: vaxis.Style{
-			Background: vaxis.ColorNavy,
-		},
-	}
+	filler := vxlayout.FillRole("·", Filler)
 	defaultopts := vxlayout.Options{}
 
 	header := func(s string) vxfw.Widget {
-		t := text.New(s)
-		t.Style.Background = vaxis.ColorGray
-		return vxlayout.Row([]vxfw.Widget{t}, defaultopts)
+		return vxlayout.Row([]vxfw.Widget{theme.Text(s, theme.Header)}, defaultopts)
 	}
 
 	return vxlayout.Column([]vxfw.Widget{
@@ -81,7 +85,7 @@ func makeScreen1() vxfw.Widget {
 			text.New("ONE"),
 			text.New("TWO"),
 			text.New("THREE"),
-			vxlayout.Flexible(vxlayout.Constrained(vxlayout.Fill(filler), &vxfw.Size{Width: 20}, &vxfw.Size{Width: 20}), 1),
+			vxlayout.Flexible(vxlayout.Constrained(filler, &vxfw.Size{Width: 20}, &vxfw.Size{Width: 20}), 1),
 		}, vxlayout.Options{
 			MainAxis: vxlayout.MainAxisEnd,
 			Gap:      2,
@@ -91,20 +95,44 @@ func makeScreen1() vxfw.Widget {
 		header("Three widgets with a 2 col gap and filler in between."),
 		vxlayout.Constrained(vxlayout.Row([]vxfw.Widget{
 			text.New("ONE"),
-			vxlayout.Expanded(vxlayout.Fill(filler), 1),
+			vxlayout.Expanded(filler, 1),
 			text.New("TWO"),
-			vxlayout.Expanded(vxlayout.Fill(filler), 1),
+			vxlayout.Expanded(filler, 1),
 			text.New("THREE"),
 		}, vxlayout.Options{
 			Gap: 2,
 		}), &vxfw.Size{Height: 1}, &vxfw.Size{Height: 1}),
+
+		// row 5
+		vxlayout.HDivider("Three widgets separated by dividers"),
+		vxlayout.Constrained(vxlayout.Row([]vxfw.Widget{
+			text.New("ONE"),
+			text.New("TWO"),
+			text.New("THREE"),
+		}, vxlayout.Options{
+			MainAxis:  vxlayout.MainAxisCenter,
+			Gap:       1,
+			Separator: vxlayout.VDivider(""),
+		}), &vxfw.Size{Height: 1}, &vxfw.Size{Height: 1}),
 	}, vxlayout.Options{
 		Gap: 1,
 	})
 }
 
 func (a *App) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
-	root := vxfw.NewSurface(ctx.Max.Width, ctx.Max.Height, a)
+	// The theme is provided within the app rather than around it, so the app stays the root
+	// widget, which has focus and captures keys
+	content, err := theme.Provide(a.theme, vxexp.WidgetFunc(a.drawContent)).Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	root := vxfw.Surface{Size: content.Size, Widget: a}
+	root.AddChild(0, 0, content)
+	return root, nil
+}
+
+func (a *App) drawContent(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	root := vxfw.NewSurface(ctx.Max.Width, ctx.Max.Height, nil)
 
 	infobar, err := a.infobar.Draw(vxfw.DrawContext{
 		Max:        vxfw.Size{Width: ctx.Max.Width, Height: 1},
@@ -133,9 +161,14 @@ func main() {
 	}
 
 	app := &App{
-		infobar: text.New("Ctrl+C to quit, L (or l) to switch layouts."),
 		screens: []vxfw.Widget{makeScreen1()},
+		theme: theme.Default.Extend(map[theme.Role]vaxis.Style{
+			theme.Header: {Background: vaxis.ColorGray},
+			Filler:       {Background: vaxis.ColorNavy},
+		}),
 	}
+	app.bindKeys()
+	app.infobar = keymap.NewKeyHints(app.keys)
 
 	vxapp.Run(app)
 }
@@ -0,0 +1,355 @@
+package keymap
+
+import (
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/richtext"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// entry is an action and the keys bound to it in a single scope.
+type entry struct {
+	action *Action
+	keys   []Sequence
+}
+
+// String returns the keys of e, with alternatives in parentheses: "L (or l)".
+func (e entry) String() string {
+	s := e.keys[0].String()
+	if len(e.keys) > 1 {
+		alts := make([]string, len(e.keys)-1)
+		for i, seq := range e.keys[1:] {
+			alts[i] = seq.String()
+		}
+		s += " (or " + strings.Join(alts, ", ") + ")"
+	}
+	return s
+}
+
+// description returns the description of the action, or its name if it has none.
+func (e entry) description() string {
+	if e.action.Description != "" {
+		return e.action.Description
+	}
+	return e.action.Name
+}
+
+// scopeEntries is the active bindings of a scope, grouped by action.
+type scopeEntries struct {
+	scope   *Keymap
+	entries []entry
+}
+
+// active returns the bindings reachable from k, grouped by scope from the innermost outwards.
+// Bindings shadowed by an inner scope are left out, as are bindings which don't continue pending.
+func active(k *Keymap, pending []vaxis.Key) []scopeEntries {
+	var out []scopeEntries
+	chain := k.Chain()
+	for i, scope := range chain {
+		group := scopeEntries{scope: scope}
+		for _, b := range scope.bindings {
+			if len(pending) > 0 && (len(b.Keys) <= len(pending) || !b.matches(pending)) {
+				continue
+			}
+			if shadowed(b, chain[:i]) {
+				continue
+			}
+
+			found := false
+			for j := range group.entries {
+				if group.entries[j].action == b.Action {
+					group.entries[j].keys = append(group.entries[j].keys, b.Keys)
+					found = true
+					break
+				}
+			}
+			if !found {
+				group.entries = append(group.entries, entry{action: b.Action, keys: []Sequence{b.Keys}})
+			}
+		}
+		if len(group.entries) > 0 {
+			out = append(out, group)
+		}
+	}
+	return out
+}
+
+// shadowed reports whether b can't be reached because a binding in one of the inner scopes
+// matches first.
+func shadowed(b Binding, inner []*Keymap) bool {
+	for _, scope := range inner {
+		for _, other := range scope.bindings {
+			if b.Keys.hasPrefix(other.Keys) || other.Keys.hasPrefix(b.Keys) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// width returns the number of columns s takes.
+func width(ctx vxfw.DrawContext, s string) uint16 {
+	var w uint16
+	for _, char := range ctx.Characters(s) {
+		w += uint16(char.Width)
+	}
+	return w
+}
+
+// KeyHints is a [vxfw.Widget] which draws a single row of hints for the bindings reachable from
+// Keymap, such as "Ctrl+C to quit, L (or l) to switch layouts." Bindings of the innermost scope
+// come first, and hints which don't fit are dropped. While a chord is pending, only the bindings
+// which complete it are shown.
+//
+// Actions without a description aren't shown. Set Keymap to the keymap of the focused widget
+// to keep the hints relevant.
+type KeyHints struct {
+	Keymap *Keymap
+
+	Style    vaxis.Style
+	KeyStyle vaxis.Style
+
+	// Join is placed between the keys and the description of a hint.
+	Join string
+	// Separator is placed between hints.
+	Separator string
+	// End is placed after the last hint. If some hints are dropped, the last hint is followed by
+	// Separator and an ellipsis instead.
+	End string
+}
+
+// NewKeyHints returns [KeyHints] for k.
+func NewKeyHints(k *Keymap) *KeyHints {
+	return &KeyHints{
+		Keymap:    k,
+		Join:      " to ",
+		Separator: ", ",
+		End:       ".",
+	}
+}
+
+var _ vxfw.Widget = &KeyHints{}
+
+func (h *KeyHints) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	var hints []entry
+	for _, group := range active(h.Keymap, h.Keymap.Pending()) {
+		for _, e := range group.entries {
+			if e.action.Description != "" {
+				hints = append(hints, e)
+			}
+		}
+	}
+
+	const ellipsis = "…"
+	var (
+		segments []vaxis.Segment
+		used     uint16
+	)
+	for i, e := range hints {
+		hint := []vaxis.Segment{
+			{Text: e.String(), Style: h.KeyStyle},
+			{Text: h.Join + e.action.Description, Style: h.Style},
+		}
+		if i > 0 {
+			hint = append([]vaxis.Segment{{Text: h.Separator, Style: h.Style}}, hint...)
+		}
+
+		// Leave room to end the row, either with End or with an ellipsis if there are more
+		// hints after this one.
+		end := h.End
+		if i < len(hints)-1 {
+			end = h.Separator + ellipsis
+		}
+		w := width(ctx, end)
+		for _, seg := range hint {
+			w += width(ctx, seg.Text)
+		}
+		if !ctx.Max.HasUnboundedWidth() && used+w > ctx.Max.Width {
+			if i > 0 {
+				segments = append(segments, vaxis.Segment{Text: h.Separator + ellipsis, Style: h.Style})
+			}
+			break
+		}
+
+		segments = append(segments, hint...)
+		used += w - width(ctx, end)
+		if i == len(hints)-1 {
+			segments = append(segments, vaxis.Segment{Text: h.End, Style: h.Style})
+		}
+	}
+
+	t := richtext.New(segments)
+	t.Softwrap = false
+	content, err := t.Draw(ctx.WithConstraints(vxfw.Size{}, vxfw.Size{Width: ctx.Max.Width, Height: 1}))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	s := vxfw.Surface{Size: vxfw.Size{Width: content.Size.Width, Height: 1}, Widget: h}
+	if !ctx.Max.HasUnboundedWidth() {
+		s.Size.Width = ctx.Max.Width
+	}
+	s.AddChild(0, 0, content)
+	return s, nil
+}
+
+// HelpOverlay is a [vxfw.Widget] which lists the bindings reachable from Keymap, grouped by scope
+// from the innermost outwards. Groups are laid out in as many columns as needed to fit the
+// available height. It takes its intrinsic size, and is typically shown in a [modal.Modal].
+//
+// Actions without a description are listed by name.
+//
+// [modal.Modal]: https://pkg.go.dev/github.com/avidal/vxexp/modal#Modal
+type HelpOverlay struct {
+	Keymap *Keymap
+
+	TitleStyle       vaxis.Style
+	KeyStyle         vaxis.Style
+	DescriptionStyle vaxis.Style
+
+	// Gap is the number of columns between columns of bindings.
+	Gap uint16
+}
+
+// NewHelpOverlay returns a [HelpOverlay] for k.
+func NewHelpOverlay(k *Keymap) *HelpOverlay {
+	return &HelpOverlay{
+		Keymap:     k,
+		TitleStyle: vaxis.Style{Attribute: vaxis.AttrBold},
+		KeyStyle:   vaxis.Style{Foreground: vaxis.IndexColor(6)},
+		Gap:        4,
+	}
+}
+
+var _ vxfw.Widget = &HelpOverlay{}
+
+// helpLine is a line of a [HelpOverlay]: a scope title, a binding, or an empty line between
+// groups.
+type helpLine struct {
+	title       string
+	keys        string
+	description string
+	width       uint16
+}
+
+func (h *HelpOverlay) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	var groups [][]helpLine
+	for _, group := range active(h.Keymap, h.Keymap.Pending()) {
+		var keyWidth uint16
+		for _, e := range group.entries {
+			if w := width(ctx, e.String()); w > keyWidth {
+				keyWidth = w
+			}
+		}
+
+		title := group.scope.Name
+		lines := []helpLine{{title: title, width: width(ctx, title)}}
+		for _, e := range group.entries {
+			// Descriptions are aligned two columns after the widest keys of the group
+			keys := e.String()
+			keys += strings.Repeat(" ", int(keyWidth-width(ctx, keys))+2)
+			lines = append(lines, helpLine{
+				keys:        keys,
+				description: e.description(),
+				width:       keyWidth + 2 + width(ctx, e.description()),
+			})
+		}
+		groups = append(groups, lines)
+	}
+
+	height := int(ctx.Max.Height)
+	if ctx.Max.HasUnboundedHeight() {
+		height = 0
+	}
+
+	// Fill columns greedily. Groups in the same column are separated by an empty line, and
+	// groups which are taller than a column are split.
+	var columns [][]helpLine
+	var current []helpLine
+	for _, lines := range groups {
+		if len(current) > 0 {
+			if height > 0 && len(current)+1+len(lines) > height {
+				columns = append(columns, current)
+				current = nil
+			} else {
+				current = append(current, helpLine{})
+			}
+		}
+		for _, line := range lines {
+			if height > 0 && len(current) == height {
+				columns = append(columns, current)
+				current = nil
+			}
+			current = append(current, line)
+		}
+	}
+	if len(current) > 0 {
+		columns = append(columns, current)
+	}
+
+	var size vxfw.Size
+	widgets := make([]vxfw.Widget, len(columns))
+	for i, lines := range columns {
+		var colWidth uint16
+		children := make([]vxfw.Widget, len(lines))
+		for j, line := range lines {
+			children[j] = h.line(line)
+			if line.width > colWidth {
+				colWidth = line.width
+			}
+		}
+
+		if i > 0 {
+			size.Width += h.Gap
+		}
+		size.Width += colWidth
+		if len(lines) > int(size.Height) {
+			size.Height = uint16(len(lines))
+		}
+		widgets[i] = vxlayout.Column(children, vxlayout.Options{CrossAxis: vxlayout.CrossAxisStart})
+	}
+
+	if size.Width > ctx.Max.Width {
+		size.Width = ctx.Max.Width
+	}
+	if size.Height > ctx.Max.Height {
+		size.Height = ctx.Max.Height
+	}
+	s := vxfw.NewSurface(size.Width, size.Height, h)
+	if len(widgets) == 0 {
+		return s, nil
+	}
+
+	row := vxlayout.Row(widgets, vxlayout.Options{Gap: h.Gap})
+	content, err := row.Draw(ctx.WithConstraints(vxfw.Size{}, size))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	s.AddChild(0, 0, content)
+	return s, nil
+}
+
+// line returns the widget which draws a line of the overlay.
+func (h *HelpOverlay) line(line helpLine) vxfw.Widget {
+	if line.keys == "" {
+		title := line.title
+		if title == "" {
+			// An empty text has no height
+			title = " "
+		}
+		t := text.New(title)
+		t.Softwrap = false
+		t.Style = h.TitleStyle
+		return t
+	}
+
+	t := richtext.New([]vaxis.Segment{
+		{Text: line.keys, Style: h.KeyStyle},
+		{Text: line.description, Style: h.DescriptionStyle},
+	})
+	t.Softwrap = false
+	return t
+}
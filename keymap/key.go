@@ -0,0 +1,177 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// Key is a single key press, with modifiers.
+type Key struct {
+	Code      rune
+	Modifiers vaxis.ModifierMask
+}
+
+// Matches reports whether ev is a press of k.
+func (k Key) Matches(ev vaxis.Key) bool {
+	return ev.Matches(k.Code, k.Modifiers)
+}
+
+func (k Key) String() string {
+	var b strings.Builder
+	for _, m := range modifierNames {
+		if k.Modifiers&m.mod != 0 {
+			b.WriteString(m.display)
+			b.WriteString("+")
+		}
+	}
+
+	for _, kn := range keyNames {
+		if kn.code == k.Code {
+			b.WriteString(kn.display)
+			return b.String()
+		}
+	}
+	if k.Code >= 'a' && k.Code <= 'z' && k.Modifiers != 0 {
+		// Letters are shown uppercase when combined with modifiers, like Ctrl+X
+		b.WriteRune(k.Code - 'a' + 'A')
+	} else {
+		b.WriteRune(k.Code)
+	}
+	return b.String()
+}
+
+// Sequence is a sequence of key presses. A sequence with more than one key is a chord.
+type Sequence []Key
+
+func (s Sequence) String() string {
+	keys := make([]string, len(s))
+	for i, k := range s {
+		keys[i] = k.String()
+	}
+	return strings.Join(keys, " ")
+}
+
+// hasPrefix reports whether prefix is a prefix of s.
+func (s Sequence) hasPrefix(prefix Sequence) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, k := range prefix {
+		if s[i] != k {
+			return false
+		}
+	}
+	return true
+}
+
+var modifierNames = []struct {
+	name    string
+	display string
+	mod     vaxis.ModifierMask
+}{
+	{"ctrl", "Ctrl", vaxis.ModCtrl},
+	{"alt", "Alt", vaxis.ModAlt},
+	{"shift", "Shift", vaxis.ModShift},
+	{"super", "Super", vaxis.ModSuper},
+	{"meta", "Meta", vaxis.ModMeta},
+	{"hyper", "Hyper", vaxis.ModHyper},
+}
+
+var keyNames = []struct {
+	names   []string
+	display string
+	code    rune
+}{
+	{[]string{"enter", "return"}, "Enter", vaxis.KeyEnter},
+	{[]string{"esc", "escape"}, "Esc", vaxis.KeyEsc},
+	{[]string{"tab"}, "Tab", vaxis.KeyTab},
+	{[]string{"space"}, "Space", vaxis.KeySpace},
+	{[]string{"backspace"}, "Backspace", vaxis.KeyBackspace},
+	{[]string{"up"}, "Up", vaxis.KeyUp},
+	{[]string{"down"}, "Down", vaxis.KeyDown},
+	{[]string{"left"}, "Left", vaxis.KeyLeft},
+	{[]string{"right"}, "Right", vaxis.KeyRight},
+	{[]string{"insert", "ins"}, "Insert", vaxis.KeyInsert},
+	{[]string{"delete", "del"}, "Delete", vaxis.KeyDelete},
+	{[]string{"pgup", "pageup", "page_up"}, "PgUp", vaxis.KeyPgUp},
+	{[]string{"pgdown", "pagedown", "page_down"}, "PgDown", vaxis.KeyPgDown},
+	{[]string{"home"}, "Home", vaxis.KeyHome},
+	{[]string{"end"}, "End", vaxis.KeyEnd},
+	{[]string{"f1"}, "F1", vaxis.KeyF01},
+	{[]string{"f2"}, "F2", vaxis.KeyF02},
+	{[]string{"f3"}, "F3", vaxis.KeyF03},
+	{[]string{"f4"}, "F4", vaxis.KeyF04},
+	{[]string{"f5"}, "F5", vaxis.KeyF05},
+	{[]string{"f6"}, "F6", vaxis.KeyF06},
+	{[]string{"f7"}, "F7", vaxis.KeyF07},
+	{[]string{"f8"}, "F8", vaxis.KeyF08},
+	{[]string{"f9"}, "F9", vaxis.KeyF09},
+	{[]string{"f10"}, "F10", vaxis.KeyF10},
+	{[]string{"f11"}, "F11", vaxis.KeyF11},
+	{[]string{"f12"}, "F12", vaxis.KeyF12},
+}
+
+// ParseKey parses a single key, written as zero or more modifiers followed by a key, separated by
+// "+". For example: "q", "ctrl+x", "alt+shift+up", "f1", "space" or "+".
+func ParseKey(s string) (Key, error) {
+	var k Key
+	rest := s
+	for {
+		i := strings.Index(rest, "+")
+		// A trailing "+" is the plus key itself
+		if i < 0 || i == len(rest)-1 {
+			break
+		}
+		name := strings.ToLower(rest[:i])
+		found := false
+		for _, m := range modifierNames {
+			if m.name == name {
+				k.Modifiers |= m.mod
+				found = true
+				break
+			}
+		}
+		if !found {
+			return Key{}, fmt.Errorf("keymap: unknown modifier %q in %q", rest[:i], s)
+		}
+		rest = rest[i+1:]
+	}
+
+	if r, n := utf8.DecodeRuneInString(rest); n > 0 && n == len(rest) {
+		k.Code = r
+		return k, nil
+	}
+
+	name := strings.ToLower(rest)
+	for _, kn := range keyNames {
+		for _, n := range kn.names {
+			if n == name {
+				k.Code = kn.code
+				return k, nil
+			}
+		}
+	}
+	return Key{}, fmt.Errorf("keymap: unknown key %q in %q", rest, s)
+}
+
+// Parse parses a sequence of space separated keys, such as "g g" or "ctrl+x ctrl+s". See
+// [ParseKey] for the syntax of each key.
+func Parse(s string) (Sequence, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("keymap: empty key sequence")
+	}
+
+	seq := make(Sequence, len(fields))
+	for i, f := range fields {
+		k, err := ParseKey(f)
+		if err != nil {
+			return nil, err
+		}
+		seq[i] = k
+	}
+	return seq, nil
+}
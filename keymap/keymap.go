@@ -0,0 +1,310 @@
+// Package keymap maps key sequences, including multi-key chords like "g g" or "ctrl+x ctrl+s", to
+// named actions.
+//
+// Keymaps are arranged in a tree of scopes. A widget's keymap falls back to its parent when a key
+// doesn't match any of its own bindings, and bindings in a child scope shadow the same keys in
+// its parents:
+//
+//	global := keymap.New("Global", nil)
+//	global.Define("quit", "Quit", quit)
+//	global.MustBind("ctrl+c", "quit")
+//
+//	editor := keymap.New("Editor", global)
+//	editor.Define("save", "Save the file", save)
+//	editor.MustBind("ctrl+x ctrl+s", "save")
+//
+//	root := keymap.Scope(editor, editorWidget)
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp"
+)
+
+var (
+	// ErrConflict is returned when binding a sequence that is equal to, or a prefix of, a
+	// sequence already bound in the same keymap. Such a binding could never be reached.
+	ErrConflict = errors.New("keymap: conflicting binding")
+	// ErrUnknownAction is returned when binding keys to an action that isn't defined.
+	ErrUnknownAction = errors.New("keymap: unknown action")
+)
+
+// DefaultTimeout is how long a partial chord waits for its next key.
+const DefaultTimeout = time.Second
+
+// Action is a named operation which can be bound to keys.
+type Action struct {
+	Name        string
+	Description string
+	Run         func() (vxfw.Command, error)
+}
+
+// Binding is a key sequence bound to an [Action].
+type Binding struct {
+	Keys   Sequence
+	Action *Action
+}
+
+// Keymap is a scope of key bindings. See the package documentation for an overview.
+// The zero value is not usable, use [New].
+type Keymap struct {
+	// Name identifies the scope, for example when listing bindings in a help screen.
+	Name   string
+	Parent *Keymap
+
+	// Timeout is how long a partial chord waits for its next key. Only the timeout of the root
+	// keymap (the one without a parent) is used.
+	Timeout time.Duration
+
+	actions  map[string]*Action
+	bindings []Binding
+
+	// Pending keys of a partial chord. Only the root keymap tracks pending keys, so chords work
+	// the same no matter which scope receives them.
+	pending   []vaxis.Key
+	pendingAt time.Time
+	now       func() time.Time
+}
+
+// New returns an empty [Keymap] named name, which falls back to parent. parent may be nil.
+func New(name string, parent *Keymap) *Keymap {
+	return &Keymap{
+		Name:    name,
+		Parent:  parent,
+		Timeout: DefaultTimeout,
+		actions: make(map[string]*Action),
+		now:     time.Now,
+	}
+}
+
+// Define defines an action in k, replacing any action with the same name in k.
+func (k *Keymap) Define(name, description string, run func() (vxfw.Command, error)) *Action {
+	a := &Action{Name: name, Description: description, Run: run}
+	k.actions[name] = a
+	return a
+}
+
+// Action returns the action called name, looking in k and then its parents.
+func (k *Keymap) Action(name string) (*Action, bool) {
+	for scope := k; scope != nil; scope = scope.Parent {
+		if a, ok := scope.actions[name]; ok {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// Bind binds keys to the action called name, which must be defined in k or one of its parents.
+// See [Parse] for the syntax of keys.
+// It's an error to bind a sequence which is equal to, is a prefix of, or starts with a sequence
+// already bound in k. Bindings in parents are shadowed instead.
+func (k *Keymap) Bind(keys string, name string) error {
+	seq, err := Parse(keys)
+	if err != nil {
+		return err
+	}
+
+	action, ok := k.Action(name)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownAction, name)
+	}
+
+	for _, b := range k.bindings {
+		if b.Keys.hasPrefix(seq) || seq.hasPrefix(b.Keys) {
+			return fmt.Errorf("%w: %q conflicts with %q (%s) in %s",
+				ErrConflict, seq, b.Keys, b.Action.Name, k.Name)
+		}
+	}
+
+	k.bindings = append(k.bindings, Binding{Keys: seq, Action: action})
+	return nil
+}
+
+// MustBind is like [Keymap.Bind] but panics if the binding fails.
+func (k *Keymap) MustBind(keys string, name string) {
+	if err := k.Bind(keys, name); err != nil {
+		panic(err)
+	}
+}
+
+// Bindings returns the bindings defined in k, not including its parents.
+func (k *Keymap) Bindings() []Binding {
+	out := make([]Binding, len(k.bindings))
+	copy(out, k.bindings)
+	return out
+}
+
+// Actions returns the actions defined in k, not including its parents, sorted by name.
+func (k *Keymap) Actions() []*Action {
+	out := make([]*Action, 0, len(k.actions))
+	for _, a := range k.actions {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Keys returns the sequences which run the action called name when pressed in k, leaving out
+// bindings in parents which are shadowed by k or its other parents.
+func (k *Keymap) Keys(name string) []Sequence {
+	action, ok := k.Action(name)
+	if !ok {
+		return nil
+	}
+
+	var out []Sequence
+	for _, group := range active(k, nil) {
+		for _, e := range group.entries {
+			if e.action == action {
+				out = append(out, e.keys...)
+			}
+		}
+	}
+	return out
+}
+
+// Chain returns k followed by each of its parents.
+func (k *Keymap) Chain() []*Keymap {
+	var out []*Keymap
+	for scope := k; scope != nil; scope = scope.Parent {
+		out = append(out, scope)
+	}
+	return out
+}
+
+func (k *Keymap) root() *Keymap {
+	root := k
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return root
+}
+
+// Pending returns the keys of the partial chord waiting for its next key, if any.
+func (k *Keymap) Pending() []vaxis.Key {
+	root := k.root()
+	if len(root.pending) > 0 && root.now().Sub(root.pendingAt) > root.Timeout {
+		root.pending = nil
+	}
+	out := make([]vaxis.Key, len(root.pending))
+	copy(out, root.pending)
+	return out
+}
+
+// HandleKey resolves ev against the bindings of k and its parents, and reports whether it was
+// handled. A key which completes a binding runs its action, and a key which starts or continues a
+// chord is held until the chord completes, fails or times out.
+func (k *Keymap) HandleKey(ev vaxis.Key) (vxfw.Command, bool, error) {
+	if ev.EventType == vaxis.EventRelease || isModifier(ev.Keycode) {
+		return nil, false, nil
+	}
+
+	root := k.root()
+	pending := k.Pending()
+	seq := append(pending, ev)
+
+	for scope := k; scope != nil; scope = scope.Parent {
+		partial := false
+		for _, b := range scope.bindings {
+			if !b.matches(seq) {
+				continue
+			}
+			if len(b.Keys) > len(seq) {
+				partial = true
+				continue
+			}
+
+			root.pending = nil
+			cmd, err := b.Action.Run()
+			return cmd, true, err
+		}
+
+		// The innermost scope with a partial match takes the key
+		if partial {
+			root.pending = seq
+			root.pendingAt = root.now()
+			return nil, true, nil
+		}
+	}
+
+	// A chord was abandoned, try the key on its own
+	if len(pending) > 0 {
+		root.pending = nil
+		return k.HandleKey(ev)
+	}
+	return nil, false, nil
+}
+
+// HandleEvent handles key events with [Keymap.HandleKey]. Handled keys are consumed.
+func (k *Keymap) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	key, ok := ev.(vaxis.Key)
+	if !ok {
+		return nil, nil
+	}
+	cmd, handled, err := k.HandleKey(key)
+	if err != nil || !handled {
+		return cmd, err
+	}
+	return []vxfw.Command{cmd, vxfw.ConsumeAndRedraw()}, nil
+}
+
+// Handler returns k as an [vxexp.EventHandlerFunc], which can be called from an existing event
+// handler or capturer. For example, to handle global bindings before any widget sees them:
+//
+//	func (a *App) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+//		return a.keys.Handler()(ev, vxfw.CapturePhase)
+//	}
+func (k *Keymap) Handler() vxexp.EventHandlerFunc {
+	return k.HandleEvent
+}
+
+// matches reports whether seq matches the start of b.
+func (b Binding) matches(seq []vaxis.Key) bool {
+	if len(seq) > len(b.Keys) {
+		return false
+	}
+	for i, ev := range seq {
+		if !b.Keys[i].Matches(ev) {
+			return false
+		}
+	}
+	return true
+}
+
+// isModifier reports whether code is a modifier key pressed by itself, which never interrupts a
+// chord.
+func isModifier(code rune) bool {
+	return code >= vaxis.KeyLeftShift && code <= vaxis.KeyL5Shift
+}
+
+// Scope returns a [vxfw.Widget] which draws child and handles keys with k when child, or one of
+// its descendants, has focus. Keys that aren't bound in k or its parents continue to bubble up to
+// the ancestors of the scope.
+func Scope(k *Keymap, child vxfw.Widget) vxfw.Widget {
+	return &scope{keymap: k, child: child}
+}
+
+type scope struct {
+	keymap *Keymap
+	child  vxfw.Widget
+}
+
+func (s *scope) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	return s.keymap.HandleEvent(ev, ph)
+}
+
+func (s *scope) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	child, err := s.child.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	out := vxfw.Surface{Size: child.Size, Widget: s}
+	out.AddChild(0, 0, child)
+	return out, nil
+}
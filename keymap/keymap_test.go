@@ -0,0 +1,246 @@
+package keymap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"g g", "g g"},
+		{"ctrl+x ctrl+s", "Ctrl+X Ctrl+S"},
+		{"Alt+Shift+Up", "Alt+Shift+Up"},
+		{"ctrl++", "Ctrl++"},
+		{"esc", "Esc"},
+	}
+	for _, tt := range tests {
+		seq, err := Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := seq.String(); got != tt.want {
+			t.Logf("wrong sequence for %q, got=%q, want=%q", tt.in, got, tt.want)
+			t.Fail()
+		}
+	}
+
+	if _, err := Parse("hyperctrl+x"); err == nil {
+		t.Logf("expected an error for an unknown modifier")
+		t.Fail()
+	}
+}
+
+func TestBindConflicts(t *testing.T) {
+	k := New("test", nil)
+	k.Define("top", "", nil)
+	k.MustBind("g g", "top")
+
+	for _, keys := range []string{"g g", "g", "g g g"} {
+		if err := k.Bind(keys, "top"); !errors.Is(err, ErrConflict) {
+			t.Logf("wrong error binding %q, got=%v, want=%v", keys, err, ErrConflict)
+			t.Fail()
+		}
+	}
+
+	if err := k.Bind("x", "missing"); !errors.Is(err, ErrUnknownAction) {
+		t.Logf("wrong error for unknown action, got=%v, want=%v", err, ErrUnknownAction)
+		t.Fail()
+	}
+
+	// A child scope may shadow its parent
+	child := New("child", k)
+	if err := child.Bind("g", "top"); err != nil {
+		t.Logf("unexpected error shadowing a parent binding: %v", err)
+		t.Fail()
+	}
+}
+
+func TestHandleKey(t *testing.T) {
+	var ran []string
+	action := func(name string) func() (vxfw.Command, error) {
+		return func() (vxfw.Command, error) {
+			ran = append(ran, name)
+			return nil, nil
+		}
+	}
+
+	now := time.Now()
+	global := New("global", nil)
+	global.now = func() time.Time { return now }
+	global.Define("save", "", action("save"))
+	global.Define("quit", "", action("quit"))
+	global.MustBind("ctrl+x ctrl+s", "save")
+	global.MustBind("q", "quit")
+
+	local := New("local", global)
+	local.Define("top", "", action("top"))
+	local.MustBind("g g", "top")
+
+	press := func(code rune, mods vaxis.ModifierMask) bool {
+		_, handled, err := local.HandleKey(vaxis.Key{Keycode: code, Modifiers: mods})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return handled
+	}
+
+	// A chord from the parent scope
+	press('x', vaxis.ModCtrl)
+	press('s', vaxis.ModCtrl)
+	// A chord from the local scope
+	press('g', 0)
+	press('g', 0)
+	// An abandoned chord retries the key on its own
+	press('g', 0)
+	press('q', 0)
+	// A timed out chord is discarded
+	press('g', 0)
+	now = now.Add(2 * DefaultTimeout)
+	press('g', 0)
+	if len(local.Pending()) != 1 {
+		t.Logf("wrong pending keys, got=%d, want=1", len(local.Pending()))
+		t.Fail()
+	}
+	if press('z', 0) {
+		t.Logf("unbound key was handled")
+		t.Fail()
+	}
+
+	want := []string{"save", "top", "quit"}
+	if len(ran) != len(want) {
+		t.Fatalf("wrong actions, got=%v, want=%v", ran, want)
+	}
+	for i := range want {
+		if ran[i] != want[i] {
+			t.Fatalf("wrong actions, got=%v, want=%v", ran, want)
+		}
+	}
+}
+
+// rows returns the graphemes of each row of the flattened surface s.
+func rows(s vxfw.Surface) []string {
+	flat := vxexp.Flatten(s)
+	out := make([]string, flat.Size.Height)
+	for row := range out {
+		for col := 0; col < int(flat.Size.Width); col++ {
+			g := flat.Buffer[row*int(flat.Size.Width)+col].Grapheme
+			if g == "" {
+				g = " "
+			}
+			out[row] += g
+		}
+	}
+	return out
+}
+
+func TestKeyHints(t *testing.T) {
+	global := New("Global", nil)
+	global.Define("quit", "quit", nil)
+	global.Define("layout", "switch layouts", nil)
+	global.Define("hidden", "", nil)
+	global.MustBind("ctrl+c", "quit")
+	global.MustBind("L", "layout")
+	global.MustBind("l", "layout")
+	global.MustBind("h", "hidden")
+
+	local := New("Local", global)
+	local.Define("top", "go to top", nil)
+	local.MustBind("g g", "top")
+	// Shadows the global binding
+	local.MustBind("l", "top")
+
+	tests := []struct {
+		keymap *Keymap
+		width  uint16
+		want   string
+	}{
+		{global, 50, "Ctrl+C to quit, L (or l) to switch layouts.       "},
+		{local, 50, "g g (or l) to go to top, Ctrl+C to quit, …        "},
+		{global, 20, "Ctrl+C to quit, …   "},
+		{global, 10, "          "},
+	}
+	for _, tt := range tests {
+		ctx := vxfw.DrawContext{Max: vxfw.Size{Width: tt.width, Height: 1}, Characters: vaxis.Characters}
+		s, err := NewKeyHints(tt.keymap).Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rows(s)[0]; got != tt.want {
+			t.Logf("wrong hints at width %d, got=%q, want=%q", tt.width, got, tt.want)
+			t.Fail()
+		}
+	}
+
+	// Only the bindings which complete a pending chord are shown
+	if _, _, err := local.HandleKey(vaxis.Key{Keycode: 'g'}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: 1}, Characters: vaxis.Characters}
+	s, err := NewKeyHints(local).Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rows(s)[0], "g g to go to top.   "; got != want {
+		t.Logf("wrong hints for a pending chord, got=%q, want=%q", got, want)
+		t.Fail()
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	global := New("Global", nil)
+	global.Define("quit", "quit", nil)
+	global.Define("help", "", nil)
+	global.MustBind("ctrl+c", "quit")
+	global.MustBind("?", "help")
+	global.MustBind("g", "help")
+
+	local := New("Editor", global)
+	local.Define("save", "save the file", nil)
+	local.MustBind("ctrl+x ctrl+s", "save")
+	local.MustBind("g g", "save")
+
+	tests := []struct {
+		height uint16
+		want   []string
+	}{
+		{10, []string{
+			"Editor                               ",
+			"Ctrl+X Ctrl+S (or g g)  save the file",
+			"                                     ",
+			"Global                               ",
+			"Ctrl+C  quit                         ",
+			"?       help                         ",
+		}},
+		// Groups flow into columns when they don't fit
+		{3, []string{
+			"Editor                                   Global      ",
+			"Ctrl+X Ctrl+S (or g g)  save the file    Ctrl+C  quit",
+			"                                         ?       help",
+		}},
+	}
+	for _, tt := range tests {
+		ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 80, Height: tt.height}, Characters: vaxis.Characters}
+		s, err := NewHelpOverlay(local).Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := rows(s)
+		if len(got) != len(tt.want) {
+			t.Fatalf("wrong number of rows at height %d, got=%q", tt.height, got)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Logf("wrong row %d at height %d, got=%q, want=%q", i, tt.height, got[i], tt.want[i])
+				t.Fail()
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package modal provides a [Modal] widget which shows a dialog on top of the rest of the UI.
+package modal
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// Dim is a backdrop which dims the background.
+func Dim(cell vaxis.Cell) vaxis.Cell {
+	cell.Style.Attribute |= vaxis.AttrDim
+	return cell
+}
+
+// Shade returns a backdrop which replaces the background color of every cell with color, and dims
+// the foreground.
+func Shade(color vaxis.Color) func(vaxis.Cell) vaxis.Cell {
+	return func(cell vaxis.Cell) vaxis.Cell {
+		cell.Style.Background = color
+		cell.Style.Attribute |= vaxis.AttrDim
+		return cell
+	}
+}
+
+// Modal is a [vxfw.Widget] which draws a background widget, typically the rest of the
+// application, and optionally a dialog centered on top of it.
+//
+// While the dialog is open, the background is drawn as a single flattened surface so none of its
+// widgets can receive focus or mouse events: keyboard focus is trapped in the dialog. Escape
+// closes the dialog with a nil result.
+type Modal struct {
+	Background vxfw.Widget
+
+	// Width and Height size the dialog as a fraction of the available space. See
+	// [vxlayout.Fraction]. If either is 0 the dialog takes its intrinsic size on that axis.
+	Width  float64
+	Height float64
+
+	// Backdrop is applied to every cell of the background while the dialog is open.
+	Backdrop func(vaxis.Cell) vaxis.Cell
+	// CloseOnClickOutside closes the dialog with a nil result if the background is clicked.
+	CloseOnClickOutside bool
+
+	dialog   *frame
+	onClose  func(result any) (vxfw.Command, error)
+	backdrop *vxexp.EventHandlerFunc
+}
+
+// New returns a [Modal] which draws background, with no dialog open.
+func New(background vxfw.Widget) *Modal {
+	m := &Modal{
+		Background: background,
+		Backdrop:   Dim,
+	}
+
+	// The backdrop absorbs all mouse events that miss the dialog. Events within the dialog bubble
+	// up through the backdrop, since it's under the dialog, so only its own are handled.
+	backdrop := vxexp.EventHandlerFunc(func(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+		mouse, ok := ev.(vaxis.Mouse)
+		if !ok || ph != vxfw.TargetPhase {
+			return nil, nil
+		}
+		if m.CloseOnClickOutside && mouse.EventType == vaxis.EventPress {
+			cmd, err := m.Close(nil)
+			return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+		}
+		return vxfw.ConsumeEventCmd{}, nil
+	})
+	m.backdrop = &backdrop
+	return m
+}
+
+// IsOpen reports whether a dialog is shown.
+func (m *Modal) IsOpen() bool { return m.dialog != nil }
+
+// Open shows dialog, replacing any open dialog without calling its callback. onClose is called
+// with the result passed to [Modal.Close], and may be nil.
+// The returned command moves focus to the dialog, and must be returned to the application.
+func (m *Modal) Open(dialog vxfw.Widget, onClose func(result any) (vxfw.Command, error)) vxfw.Command {
+	m.dialog = &frame{child: dialog}
+	m.onClose = onClose
+	return []vxfw.Command{vxfw.FocusWidgetCmd(m.dialog), vxfw.RedrawCmd{}}
+}
+
+// Close hides the dialog and calls the onClose callback passed to [Modal.Open] with result.
+// Focus moves back to the Modal, unless the callback moves it elsewhere.
+func (m *Modal) Close(result any) (vxfw.Command, error) {
+	if m.dialog == nil {
+		return nil, nil
+	}
+
+	onClose := m.onClose
+	m.dialog, m.onClose = nil, nil
+
+	cmds := []vxfw.Command{vxfw.FocusWidgetCmd(m), vxfw.RedrawCmd{}}
+	if onClose != nil {
+		cmd, err := onClose(result)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+func (m *Modal) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+	if m.dialog == nil {
+		return nil, nil
+	}
+	key, ok := ev.(vaxis.Key)
+	if !ok || key.EventType == vaxis.EventRelease {
+		return nil, nil
+	}
+	if key.Matches(vaxis.KeyEsc) {
+		cmd, err := m.Close(nil)
+		return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+	}
+	return nil, nil
+}
+
+var _ vxfw.Widget = &Modal{}
+
+func (m *Modal) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	background, err := m.Background.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	s := vxfw.Surface{Size: background.Size, Widget: m}
+	if m.dialog == nil {
+		s.AddChild(0, 0, background)
+		return s, nil
+	}
+
+	backdrop := vxexp.Flatten(background)
+	backdrop.Widget = m.backdrop
+	if m.Backdrop != nil {
+		for i, cell := range backdrop.Buffer {
+			backdrop.Buffer[i] = m.Backdrop(cell)
+		}
+	}
+	s.AddChild(0, 0, backdrop)
+
+	dialogCtx := ctx.WithConstraints(vxfw.Size{}, background.Size)
+	dialog, err := vxlayout.Fraction(m.dialog, m.Width, m.Height).Draw(dialogCtx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	col := (int(background.Size.Width) - int(dialog.Size.Width)) / 2
+	row := (int(background.Size.Height) - int(dialog.Size.Height)) / 2
+	if col < 0 {
+		col = 0
+	}
+	if row < 0 {
+		row = 0
+	}
+
+	// The dialog is placed above the backdrop
+	sub := vxfw.NewSubSurface(col, row, dialog)
+	sub.ZIndex = 1
+	s.Children = append(s.Children, sub)
+	return s, nil
+}
+
+// frame wraps a dialog so that there is always a widget in the dialog that can hold focus, which
+// keeps the [Modal] in the focus path.
+type frame struct {
+	child vxfw.Widget
+}
+
+func (f *frame) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	child, err := f.child.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	s := vxfw.Surface{Size: child.Size, Widget: f}
+	s.AddChild(0, 0, child)
+	return s, nil
+}
@@ -0,0 +1,140 @@
+package modal
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+func TestModal(t *testing.T) {
+	background := vxlayout.Fill(vaxis.Cell{Character: vaxis.Character{Grapheme: "x", Width: 1}})
+	m := New(background)
+	m.Width = 0.5
+
+	var closed bool
+	m.Open(text.New("sure?"), func(result any) (vxfw.Command, error) {
+		closed = result == nil
+		return nil, nil
+	})
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: 5}, Characters: vaxis.Characters}
+	s, err := m.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Children) != 2 {
+		t.Fatalf("wrong number of children, got=%d, want=2", len(s.Children))
+	}
+
+	backdrop := s.Children[0].Surface
+	if len(backdrop.Children) != 0 || backdrop.Buffer[0].Style.Attribute&vaxis.AttrDim == 0 {
+		t.Logf("background should be flattened and dimmed")
+		t.Fail()
+	}
+
+	dialog := s.Children[1]
+	want := vxfw.RelativePoint{Row: 2, Col: 5}
+	if dialog.Origin != want || dialog.ZIndex != 1 || dialog.Surface.Size.Width != 10 {
+		t.Logf("wrong dialog placement, got=%v %v z=%d", dialog.Origin, dialog.Surface.Size, dialog.ZIndex)
+		t.Fail()
+	}
+
+	if _, err := m.CaptureEvent(vaxis.Key{Keycode: vaxis.KeyEsc}); err != nil {
+		t.Fatal(err)
+	}
+	if m.IsOpen() || !closed {
+		t.Logf("escape should close the dialog with a nil result")
+		t.Fail()
+	}
+}
+
+// click delivers a left button press at col, row to the widgets of s under it, as the
+// application does: the deepest widget is the target, and the event bubbles up from there.
+func click(s vxfw.Surface, col, row int) error {
+	var hits []vxfw.Widget
+	var hit func(s vxfw.Surface, col, row int)
+	hit = func(s vxfw.Surface, col, row int) {
+		hits = append(hits, s.Widget)
+		for _, child := range s.Children {
+			c, r := col-child.Origin.Col, row-child.Origin.Row
+			if c >= 0 && r >= 0 && c < int(child.Surface.Size.Width) && r < int(child.Surface.Size.Height) {
+				hit(child.Surface, c, r)
+			}
+		}
+	}
+	hit(s, col, row)
+
+	ev := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventPress, Col: col, Row: row}
+	for i := len(hits) - 1; i >= 0; i-- {
+		h, ok := hits[i].(vxfw.EventHandler)
+		if !ok {
+			continue
+		}
+		phase := vxfw.BubblePhase
+		if i == len(hits)-1 {
+			phase = vxfw.TargetPhase
+		}
+		cmd, err := h.HandleEvent(ev, phase)
+		if err != nil {
+			return err
+		}
+		if consumes(cmd) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func consumes(cmd vxfw.Command) bool {
+	switch cmd := cmd.(type) {
+	case vxfw.ConsumeEventCmd:
+		return true
+	case vxfw.BatchCmd:
+		for _, c := range cmd {
+			if consumes(c) {
+				return true
+			}
+		}
+	case []vxfw.Command:
+		for _, c := range cmd {
+			if consumes(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestModalClickOutside(t *testing.T) {
+	background := vxlayout.Fill(vaxis.Cell{Character: vaxis.Character{Grapheme: "x", Width: 1}})
+	m := New(background)
+	m.Width, m.Height = 0.5, 0.6
+	m.CloseOnClickOutside = true
+	m.Open(text.New("sure?"), nil)
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: 5}, Characters: vaxis.Characters}
+	s, err := m.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clicking the dialog, past the end of its text, doesn't close it
+	if err := click(s, 12, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsOpen() {
+		t.Fatal("clicking inside the dialog closed it")
+	}
+
+	if err := click(s, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if m.IsOpen() {
+		t.Log("clicking outside the dialog didn't close it")
+		t.Fail()
+	}
+}
@@ -0,0 +1,111 @@
+package vxexp
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// Region is a [vxfw.Widget] which tracks the mouse over its child. See [MouseRegion].
+type Region struct {
+	Child vxfw.Widget
+
+	// OnEnter is called when the mouse moves over the region, and OnExit when it leaves. OnHover
+	// is called with each mouse event over the region, before the child sees it. Any of them can
+	// be nil. The region is redrawn after each of them.
+	OnEnter func()
+	OnExit  func()
+	OnHover func(vaxis.Mouse)
+
+	// Shape, if not empty, is the shape of the mouse cursor while it's over the region.
+	Shape vaxis.MouseShape
+
+	hovered bool
+	leaving bool
+}
+
+// MouseRegion returns a [Region] which tracks the mouse over widget, calling onEnter when the mouse
+// moves over it, onExit when it leaves and onHover with each mouse event over it.
+//
+// vxfw sends [vxfw.MouseLeave] and then [vxfw.MouseEnter] each time the mouse moves within a
+// widget, so the events can't tell a widget whether the mouse is over it. A Region only reports
+// the mouse leaving if it isn't entered again straight away, which it checks when it's next drawn,
+// so OnExit is called while the region is being drawn.
+//
+// If the region stops being drawn while the mouse is over it, for example when it's scrolled out
+// of a LazyColumn or removed from the tree, OnExit isn't called until the region is drawn again,
+// which may be never. [Region.Hovered] reports false as soon as the mouse leaves, so state which
+// must not outlive the region, such as a hover style, is better read from Hovered while drawing
+// than kept with onEnter and onExit.
+func MouseRegion(widget vxfw.Widget, onEnter, onExit func(), onHover func(vaxis.Mouse)) *Region {
+	return &Region{Child: widget, OnEnter: onEnter, OnExit: onExit, OnHover: onHover}
+}
+
+// Hovered reports whether the mouse is over the region.
+func (r *Region) Hovered() bool {
+	return r.hovered && !r.leaving
+}
+
+var (
+	_ vxfw.Widget        = &Region{}
+	_ vxfw.EventCapturer = &Region{}
+	_ vxfw.EventHandler  = &Region{}
+)
+
+func (r *Region) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if r.leaving {
+		r.hovered, r.leaving = false, false
+		if r.OnExit != nil {
+			r.OnExit()
+		}
+	}
+
+	surface := vxfw.Surface{Size: ctx.Min, Widget: r}
+	if r.Child == nil {
+		return surface, nil
+	}
+	child, err := r.Child.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	surface.Size = child.Size
+	surface.AddChild(0, 0, child)
+	return surface, nil
+}
+
+func (r *Region) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok || r.OnHover == nil {
+		return nil, nil
+	}
+	r.OnHover(mouse)
+	return vxfw.RedrawCmd{}, nil
+}
+
+func (r *Region) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev.(type) {
+	case vxfw.MouseEnter:
+		r.leaving = false
+		cmds := []vxfw.Command{}
+		if r.Shape != "" {
+			cmds = append(cmds, vxfw.SetMouseShapeCmd(r.Shape))
+		}
+		if !r.hovered {
+			r.hovered = true
+			if r.OnEnter != nil {
+				r.OnEnter()
+			}
+			cmds = append(cmds, vxfw.RedrawCmd{})
+		}
+		return cmds, nil
+	case vxfw.MouseLeave:
+		// The mouse may only have moved within the region, in which case it's entered again
+		// before the region is drawn
+		r.leaving = true
+		cmds := []vxfw.Command{vxfw.RedrawCmd{}}
+		if r.Shape != "" {
+			cmds = append(cmds, vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault))
+		}
+		return cmds, nil
+	}
+	return nil, nil
+}
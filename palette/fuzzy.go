@@ -0,0 +1,109 @@
+package palette
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scoring of fuzzy matches. Each matched rune scores scoreMatch plus any bonuses, and each
+// unmatched rune between two matches costs penaltyGap.
+const (
+	scoreMatch       = 16
+	bonusStart       = 24
+	bonusBoundary    = 16
+	bonusCamel       = 12
+	bonusConsecutive = 8
+	penaltyGap       = 1
+	penaltyLeading   = 1
+	maxLeading       = 8
+)
+
+// Match reports whether the runes of pattern appear in s in order, ignoring case and spaces in
+// pattern. It returns a score, higher for better matches, and the indexes of the runes in s which
+// matched.
+// Matches at the start of words, such as after a space or punctuation or at a camelCase hump,
+// and runs of consecutive runes score higher, while gaps between matches score lower.
+func Match(pattern, s string) (score int, positions []int, ok bool) {
+	var p []rune
+	for _, r := range strings.ToLower(pattern) {
+		if !unicode.IsSpace(r) {
+			p = append(p, r)
+		}
+	}
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	runes := []rune(s)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// Try each occurrence of the first rune as the start of the match, and greedily match the
+	// rest of the pattern from there. The best scoring attempt wins.
+	var (
+		best          int
+		bestPositions []int
+	)
+	for start := range lower {
+		if lower[start] != p[0] {
+			continue
+		}
+
+		matched := []int{start}
+		for i, j := 1, start+1; i < len(p) && j < len(lower); j++ {
+			if lower[j] == p[i] {
+				matched = append(matched, j)
+				i++
+			}
+		}
+		if len(matched) < len(p) {
+			// No later start can match either
+			break
+		}
+
+		if sc := rank(runes, matched); bestPositions == nil || sc > best {
+			best, bestPositions = sc, matched
+		}
+	}
+
+	if bestPositions == nil {
+		return 0, nil, false
+	}
+	return best, bestPositions, true
+}
+
+// rank returns the score of matching the runes of s at positions.
+func rank(s []rune, positions []int) int {
+	leading := positions[0]
+	if leading > maxLeading {
+		leading = maxLeading
+	}
+	total := -leading * penaltyLeading
+
+	for i, pos := range positions {
+		total += scoreMatch
+		switch {
+		case pos == 0:
+			total += bonusStart
+		case isSeparator(s[pos-1]):
+			total += bonusBoundary
+		case unicode.IsLower(s[pos-1]) && unicode.IsUpper(s[pos]):
+			total += bonusCamel
+		}
+
+		if i > 0 {
+			if gap := pos - positions[i-1] - 1; gap == 0 {
+				total += bonusConsecutive
+			} else {
+				total -= gap * penaltyGap
+			}
+		}
+	}
+	return total
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("-_./:", r)
+}
@@ -0,0 +1,429 @@
+// Package palette provides a [CommandPalette], an overlay which finds and runs commands by
+// fuzzy matching their names as the user types.
+package palette
+
+import (
+	"sort"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/textfield"
+	"github.com/avidal/vxexp/keymap"
+	"github.com/avidal/vxexp/modal"
+)
+
+// Command is an entry in a [CommandPalette].
+type Command struct {
+	Name        string
+	Description string
+	// Shortcut is shown next to the command, for example the keys bound to it.
+	Shortcut string
+	Run      func() (vxfw.Command, error)
+}
+
+// Commands returns a [Command] for each action defined in k and its parents. Actions in parents
+// which are shadowed by an action with the same name are left out, and the shortcut of each
+// command is the first sequence of keys which runs it.
+func Commands(k *keymap.Keymap) []Command {
+	var out []Command
+	seen := make(map[string]bool)
+	for _, scope := range k.Chain() {
+		for _, a := range scope.Actions() {
+			if seen[a.Name] {
+				continue
+			}
+			seen[a.Name] = true
+
+			cmd := Command{Name: a.Name, Description: a.Description, Run: a.Run}
+			if keys := k.Keys(a.Name); len(keys) > 0 {
+				cmd.Shortcut = keys[0].String()
+			}
+			out = append(out, cmd)
+		}
+	}
+	return out
+}
+
+// StyleSet is the style of a [CommandPalette].
+// Except for Background and Selected, each style is added to the style of the row it's drawn on:
+// its colors replace those of the row if they're set, and its attributes are combined.
+type StyleSet struct {
+	Background  vaxis.Style
+	Prompt      vaxis.Style
+	Input       vaxis.Style
+	Description vaxis.Style
+	Shortcut    vaxis.Style
+	// Selected replaces Background on the row of the selected command
+	Selected vaxis.Style
+	// Match is added to the runes which matched the query
+	Match vaxis.Style
+}
+
+// CommandPalette is a [vxfw.Widget] which draws Child, and shows a palette of commands on top of
+// it when opened. Typing filters the commands with [Match], the arrow keys (or Ctrl+N and Ctrl+P)
+// move the selection, and Enter or a click closes the palette and runs the selected command.
+// Escape closes the palette without running anything.
+//
+// The palette is typically opened from a key binding:
+//
+//	p := palette.New(app, palette.Commands(keys))
+//	keys.Define("palette", "Show all commands", func() (vxfw.Command, error) {
+//		return p.Open(), nil
+//	})
+type CommandPalette struct {
+	Commands []Command
+
+	Prompt string
+	Style  StyleSet
+	// Width is the maximum width of the palette
+	Width uint16
+	// MaxResults is the number of commands shown at once
+	MaxResults int
+
+	modal   *modal.Modal
+	dialog  *dialog
+	input   *textfield.TextField
+	results []*result
+	// selected is the index of the selected result, and offset the index of the first result
+	// shown.
+	selected int
+	offset   int
+}
+
+// New returns a closed [CommandPalette] which draws child and lists commands.
+func New(child vxfw.Widget, commands []Command) *CommandPalette {
+	p := &CommandPalette{
+		Commands: commands,
+		Prompt:   "> ",
+		Style: StyleSet{
+			Background:  vaxis.Style{Attribute: vaxis.AttrReverse},
+			Prompt:      vaxis.Style{Attribute: vaxis.AttrBold},
+			Description: vaxis.Style{Attribute: vaxis.AttrDim},
+			Shortcut:    vaxis.Style{Attribute: vaxis.AttrDim},
+			Match:       vaxis.Style{Attribute: vaxis.AttrBold, UnderlineStyle: vaxis.UnderlineSingle},
+		},
+		Width:      60,
+		MaxResults: 10,
+		modal:      modal.New(child),
+		input:      textfield.New(),
+	}
+	p.dialog = &dialog{palette: p}
+	p.input.OnChange = func(string) (vxfw.Command, error) {
+		p.filter()
+		return vxfw.RedrawCmd{}, nil
+	}
+	return p
+}
+
+// IsOpen reports whether the palette is shown.
+func (p *CommandPalette) IsOpen() bool { return p.modal.IsOpen() }
+
+// Open shows the palette with an empty query. The returned command moves focus to the palette,
+// and must be returned to the application.
+func (p *CommandPalette) Open() vxfw.Command {
+	p.input.Reset()
+	p.filter()
+	return []vxfw.Command{p.modal.Open(p.dialog, nil), vxfw.FocusWidgetCmd(p.input)}
+}
+
+// Close hides the palette without running a command.
+func (p *CommandPalette) Close() (vxfw.Command, error) {
+	return p.modal.Close(nil)
+}
+
+// Query returns the text typed into the palette.
+func (p *CommandPalette) Query() string { return p.input.Value }
+
+// SetQuery replaces the text typed into the palette.
+func (p *CommandPalette) SetQuery(query string) {
+	p.input.Reset()
+	p.input.InsertStringAtCursor(query)
+	p.filter()
+}
+
+// Matches returns the commands matching the query, best match first.
+func (p *CommandPalette) Matches() []Command {
+	out := make([]Command, len(p.results))
+	for i, r := range p.results {
+		out[i] = r.command
+	}
+	return out
+}
+
+// Selected returns the selected command, if any command matches the query.
+func (p *CommandPalette) Selected() (Command, bool) {
+	if len(p.results) == 0 {
+		return Command{}, false
+	}
+	return p.results[p.selected].command, true
+}
+
+// Select moves the selection by delta, wrapping around at either end.
+func (p *CommandPalette) Select(delta int) {
+	if len(p.results) == 0 {
+		return
+	}
+	p.selected = ((p.selected+delta)%len(p.results) + len(p.results)) % len(p.results)
+
+	// Keep the selection in view
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.MaxResults > 0 && p.selected >= p.offset+p.MaxResults {
+		p.offset = p.selected - p.MaxResults + 1
+	}
+}
+
+// Run closes the palette and runs the selected command.
+func (p *CommandPalette) Run() (vxfw.Command, error) {
+	if len(p.results) == 0 {
+		return nil, nil
+	}
+	return p.run(p.results[p.selected])
+}
+
+func (p *CommandPalette) run(r *result) (vxfw.Command, error) {
+	cmd, err := p.Close()
+	if err != nil || r.command.Run == nil {
+		return cmd, err
+	}
+
+	// The command runs after the palette is closed, so it can move focus elsewhere
+	run, err := r.command.Run()
+	if err != nil {
+		return nil, err
+	}
+	return []vxfw.Command{cmd, run}, nil
+}
+
+// filter matches the commands against the query, and selects the best match.
+func (p *CommandPalette) filter() {
+	p.results = p.results[:0]
+	for _, cmd := range p.Commands {
+		target := cmd.Name
+		if cmd.Description != "" {
+			target += separator + cmd.Description
+		}
+		score, positions, ok := Match(p.input.Value, target)
+		if !ok {
+			continue
+		}
+		p.results = append(p.results, &result{
+			palette:   p,
+			command:   cmd,
+			score:     score,
+			positions: positions,
+		})
+	}
+	sort.SliceStable(p.results, func(i, j int) bool {
+		return p.results[i].score > p.results[j].score
+	})
+	p.selected, p.offset = 0, 0
+}
+
+// separator is placed between the name and description of a command, which are matched and drawn
+// as a single string.
+const separator = "  "
+
+var _ vxfw.Widget = &CommandPalette{}
+
+func (p *CommandPalette) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	child, err := p.modal.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	s := vxfw.Surface{Size: child.Size, Widget: p}
+	s.AddChild(0, 0, child)
+	return s, nil
+}
+
+// dialog is the palette itself, shown in the modal: the query on the first row and the matching
+// commands below it.
+type dialog struct {
+	palette *CommandPalette
+}
+
+func (d *dialog) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+	p := d.palette
+	key, ok := ev.(vaxis.Key)
+	if !ok || key.EventType == vaxis.EventRelease {
+		return nil, nil
+	}
+
+	switch {
+	case key.Matches(vaxis.KeyUp), key.Matches('p', vaxis.ModCtrl):
+		p.Select(-1)
+	case key.Matches(vaxis.KeyDown), key.Matches('n', vaxis.ModCtrl):
+		p.Select(1)
+	case key.Matches(vaxis.KeyEnter):
+		cmd, err := p.Run()
+		return []vxfw.Command{cmd, vxfw.ConsumeAndRedraw()}, err
+	default:
+		return nil, nil
+	}
+	return vxfw.ConsumeAndRedraw(), nil
+}
+
+func (d *dialog) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	p := d.palette
+	width := p.Width
+	if width > ctx.Max.Width {
+		width = ctx.Max.Width
+	}
+
+	visible := p.results[p.offset:]
+	if p.MaxResults > 0 && len(visible) > p.MaxResults {
+		visible = visible[:p.MaxResults]
+	}
+
+	rows := uint16(len(visible))
+	if rows == 0 {
+		rows = 1
+	}
+	s := vxfw.NewSurface(width, 1+rows, d)
+	s.FillStyle(p.Style.Background)
+
+	// The query
+	col := write(ctx, &s, 0, 0, width, p.Prompt, overlay(p.Style.Background, p.Style.Prompt))
+	if col < width {
+		p.input.Style = overlay(p.Style.Background, p.Style.Input)
+		input, err := p.input.Draw(ctx.WithConstraints(
+			vxfw.Size{Width: width - col, Height: 1},
+			vxfw.Size{Width: width - col, Height: 1},
+		))
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+		s.AddChild(int(col), 0, input)
+	}
+
+	if len(visible) == 0 {
+		write(ctx, &s, 1, 1, width, "No matching commands", overlay(p.Style.Background, p.Style.Description))
+		return s, nil
+	}
+
+	rowCtx := ctx.WithConstraints(vxfw.Size{Width: width, Height: 1}, vxfw.Size{Width: width, Height: 1})
+	for i, r := range visible {
+		row, err := r.Draw(rowCtx)
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+		s.AddChild(0, 1+i, row)
+	}
+	return s, nil
+}
+
+// result is a command which matched the query.
+type result struct {
+	palette   *CommandPalette
+	command   Command
+	score     int
+	positions []int
+}
+
+func (r *result) selected() bool {
+	p := r.palette
+	return len(p.results) > 0 && p.results[p.selected] == r
+}
+
+func (r *result) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	p := r.palette
+	width := ctx.Max.Width
+
+	base := p.Style.Background
+	if r.selected() {
+		base = p.Style.Selected
+	}
+	s := vxfw.NewSurface(width, 1, r)
+	s.FillStyle(base)
+
+	// The shortcut is right aligned, and the name and description are truncated before it
+	limit := width
+	if r.command.Shortcut != "" {
+		var w uint16
+		shortcut := ctx.Characters(r.command.Shortcut)
+		for _, char := range shortcut {
+			w += uint16(char.Width)
+		}
+		if w+2 < width {
+			col := width - w - 1
+			limit = col - 1
+			write(ctx, &s, col, 0, width, r.command.Shortcut, overlay(base, p.Style.Shortcut))
+		}
+	}
+
+	matched := make(map[int]bool, len(r.positions))
+	for _, pos := range r.positions {
+		matched[pos] = true
+	}
+	name := len([]rune(r.command.Name))
+
+	col := uint16(1)
+	var i int
+	for _, char := range ctx.Characters(r.command.Name + separator + r.command.Description) {
+		if col+uint16(char.Width) > limit {
+			break
+		}
+
+		style := base
+		if i >= name {
+			style = overlay(base, p.Style.Description)
+		}
+		// A grapheme is highlighted if any of its runes matched
+		runes := len([]rune(char.Grapheme))
+		for j := i; j < i+runes; j++ {
+			if matched[j] {
+				style = overlay(style, p.Style.Match)
+				break
+			}
+		}
+		i += runes
+
+		s.WriteCell(col, 0, vaxis.Cell{Character: char, Style: style})
+		col += uint16(char.Width)
+	}
+	return s, nil
+}
+
+func (r *result) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		if ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseLeftButton {
+			cmd, err := r.palette.run(r)
+			return []vxfw.Command{cmd, vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault), vxfw.ConsumeAndRedraw()}, err
+		}
+	case vxfw.MouseEnter:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeClickable), nil
+	case vxfw.MouseLeave:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault), nil
+	}
+	return nil, nil
+}
+
+// write writes text to row of s starting at col, stopping before limit, and returns the column
+// after the text.
+func write(ctx vxfw.DrawContext, s *vxfw.Surface, col, row, limit uint16, text string, style vaxis.Style) uint16 {
+	for _, char := range ctx.Characters(text) {
+		if col+uint16(char.Width) > limit {
+			break
+		}
+		s.WriteCell(col, row, vaxis.Cell{Character: char, Style: style})
+		col += uint16(char.Width)
+	}
+	return col
+}
+
+// overlay returns base with the colors of style, if they're set, and the attributes of both.
+func overlay(base, style vaxis.Style) vaxis.Style {
+	if style.Foreground != 0 {
+		base.Foreground = style.Foreground
+	}
+	if style.Background != 0 {
+		base.Background = style.Background
+	}
+	if style.UnderlineStyle != 0 {
+		base.UnderlineStyle = style.UnderlineStyle
+	}
+	base.Attribute |= style.Attribute
+	return base
+}
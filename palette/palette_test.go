@@ -0,0 +1,143 @@
+package palette
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp/keymap"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		s         string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"sf", "save file", true, []int{0, 5}},
+		{"SF", "saveFile", true, []int{0, 4}},
+		// The start of a word is preferred over an earlier match
+		{"f", "buffer file", true, []int{7}},
+		{"fil", "fill file", true, []int{0, 1, 2}},
+		{"xyz", "save file", false, nil},
+		{"s f", "save file", true, []int{0, 5}},
+	}
+	for _, tt := range tests {
+		_, positions, ok := Match(tt.pattern, tt.s)
+		if ok != tt.ok {
+			t.Logf("wrong match of %q in %q, got=%v, want=%v", tt.pattern, tt.s, ok, tt.ok)
+			t.Fail()
+			continue
+		}
+		if len(positions) != len(tt.positions) {
+			t.Logf("wrong positions of %q in %q, got=%v, want=%v", tt.pattern, tt.s, positions, tt.positions)
+			t.Fail()
+			continue
+		}
+		for i := range positions {
+			if positions[i] != tt.positions[i] {
+				t.Logf("wrong positions of %q in %q, got=%v, want=%v", tt.pattern, tt.s, positions, tt.positions)
+				t.Fail()
+				break
+			}
+		}
+	}
+
+	// Better matches score higher
+	word, _, _ := Match("lay", "layout")
+	inner, _, _ := Match("lay", "display")
+	if word <= inner {
+		t.Logf("match at start scored %d, not higher than inner match %d", word, inner)
+		t.Fail()
+	}
+}
+
+func TestCommandPalette(t *testing.T) {
+	var ran []string
+	run := func(name string) func() (vxfw.Command, error) {
+		return func() (vxfw.Command, error) {
+			ran = append(ran, name)
+			return nil, nil
+		}
+	}
+
+	keys := keymap.New("Global", nil)
+	keys.Define("quit", "Quit the application", run("quit"))
+	keys.Define("layout", "Switch layouts", run("layout"))
+	keys.Define("display", "Toggle the display", run("display"))
+	keys.MustBind("ctrl+c", "quit")
+	keys.MustBind("L", "layout")
+
+	commands := Commands(keys)
+	if len(commands) != 3 || commands[1].Name != "layout" || commands[1].Shortcut != "L" {
+		t.Fatalf("wrong commands from keymap, got=%+v", commands)
+	}
+
+	p := New(text.New("background"), commands)
+	p.Open()
+	if !p.IsOpen() || len(p.Matches()) != 3 {
+		t.Fatalf("palette should be open with all commands, open=%v, matches=%d", p.IsOpen(), len(p.Matches()))
+	}
+
+	// Typing filters the commands through the input's change handler
+	for _, r := range "lay" {
+		if _, err := p.input.HandleEvent(vaxis.Key{Keycode: r, Text: string(r)}, vxfw.TargetPhase); err != nil {
+			t.Fatal(err)
+		}
+	}
+	matches := p.Matches()
+	if len(matches) != 2 || matches[0].Name != "layout" {
+		t.Fatalf("wrong matches for %q, got=%+v", p.Query(), matches)
+	}
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 40, Height: 10}, Characters: vaxis.Characters}
+	s, err := p.dialog.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Size.Width != 40 || s.Size.Height != 3 {
+		t.Logf("wrong palette size, got=%v, want=40x3", s.Size)
+		t.Fail()
+	}
+
+	// The matched runes are highlighted
+	row, err := p.results[0].Draw(ctx.WithConstraints(vxfw.Size{Width: 40, Height: 1}, vxfw.Size{Width: 40, Height: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for col := 1; col < 7; col++ {
+		cell := row.Buffer[col]
+		highlighted := cell.Style.UnderlineStyle == vaxis.UnderlineSingle
+		if highlighted != (col <= 3) {
+			t.Logf("wrong highlight of %q at column %d, got=%v", cell.Grapheme, col, highlighted)
+			t.Fail()
+		}
+	}
+	if got := row.Buffer[38].Grapheme; got != "L" {
+		t.Logf("wrong shortcut, got=%q, want=%q", got, "L")
+		t.Fail()
+	}
+
+	// Moving the selection wraps around, and Enter runs the selected command
+	if _, err := p.dialog.CaptureEvent(vaxis.Key{Keycode: vaxis.KeyUp}); err != nil {
+		t.Fatal(err)
+	}
+	if cmd, _ := p.Selected(); cmd.Name != "display" {
+		t.Logf("wrong selection, got=%q, want=%q", cmd.Name, "display")
+		t.Fail()
+	}
+	if _, err := p.dialog.CaptureEvent(vaxis.Key{Keycode: vaxis.KeyEnter}); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsOpen() {
+		t.Logf("palette is still open after running a command")
+		t.Fail()
+	}
+	if len(ran) != 1 || ran[0] != "display" {
+		t.Logf("wrong commands ran, got=%v, want=[display]", ran)
+		t.Fail()
+	}
+}
@@ -0,0 +1,112 @@
+package vxexp
+
+import (
+	"sync"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// Provide returns a [vxfw.Widget] which draws child, and provides value to child and all of its
+// descendants. Descendants retrieve it with [Lookup].
+//
+// Values are looked up by type, so give shared values a type of their own rather than providing
+// plain strings or ints. A provider shadows any provider of the same type above it.
+//
+// [vxfw.App] focuses the root widget it's given, so provide values to the content of the root from
+// its Draw rather than wrapping the root, which would keep the root from capturing keys.
+func Provide[T any](value T, child vxfw.Widget) vxfw.Widget {
+	return &provider[T]{
+		value: func(vxfw.DrawContext) T { return value },
+		child: child,
+	}
+}
+
+// ProvideFunc is like [Provide], but calls fn each time the widget is drawn to get the value. fn
+// can use [Lookup] on its context, for example to provide a modified copy of a value provided
+// further up the tree.
+func ProvideFunc[T any](fn func(vxfw.DrawContext) T, child vxfw.Widget) vxfw.Widget {
+	return &provider[T]{value: fn, child: child}
+}
+
+// Lookup returns the value of type T provided to the widget being drawn with ctx by its closest
+// ancestor, and whether there was one. If T is an interface type, the closest value implementing
+// T is returned.
+//
+// Values are carried by ctx.Characters, which layouts pass on to their children with the rest of
+// the context, so they reach widgets built from [WidgetFunc] as well as widgets drawn with a
+// derived context such as ctx.WithMax(...). A widget which draws its children with a context of
+// its own making must copy ctx.Characters into it, or its descendants won't find the values
+// provided above it.
+//
+// Events don't come with a context. To look up a value while handling an event, keep the context
+// of the last Draw:
+//
+//	func (w *Widget) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+//		w.ctx = ctx
+//		...
+//	}
+//
+//	func (w *Widget) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+//		store, _ := vxexp.Lookup[*Store](w.ctx)
+//		...
+//	}
+func Lookup[T any](ctx vxfw.DrawContext) (T, bool) {
+	var zero T
+	if ctx.Characters == nil {
+		return zero, false
+	}
+
+	lookup.Lock()
+	defer lookup.Unlock()
+	lookup.match = func(v any) bool {
+		_, ok := v.(T)
+		return ok
+	}
+	lookup.found, lookup.ok = nil, false
+	ctx.Characters(probe)
+	lookup.match = nil
+
+	if !lookup.ok {
+		return zero, false
+	}
+	return lookup.found.(T), true
+}
+
+// probe is passed to ctx.Characters by [Lookup]. Providers intercept it while lookup.match is set,
+// and answer through lookup if their value matches, or pass it on to the providers above them.
+// Since it has no graphemes, a probe no provider answers reaches the Characters of the
+// application without measuring or caching anything, and it's harmless when it isn't a probe.
+const probe = ""
+
+var lookup struct {
+	sync.Mutex
+	match func(any) bool
+	found any
+	ok    bool
+}
+
+type provider[T any] struct {
+	value func(vxfw.DrawContext) T
+	child vxfw.Widget
+}
+
+func (p *provider[T]) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	value := p.value(ctx)
+	chars := ctx.Characters
+	ctx.Characters = func(s string) []vaxis.Character {
+		if s == probe && lookup.match != nil && lookup.match(value) {
+			lookup.found, lookup.ok = value, true
+			return nil
+		}
+		return chars(s)
+	}
+
+	child, err := p.child.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	s := vxfw.Surface{Size: child.Size, Widget: p}
+	s.AddChild(0, 0, child)
+	return s, nil
+}
@@ -0,0 +1,290 @@
+// Package scrollbar provides a [Scrollbar] widget which shows, and controls, the position of a
+// viewport in scrollable content.
+package scrollbar
+
+import (
+	"math"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// eighths of a cell, from the least to the most filled. Vertical scrollbars use blocks filled from
+// the bottom, and horizontal scrollbars use blocks filled from the left.
+var (
+	lowerBlocks = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+	leftBlocks  = []string{" ", "▏", "▎", "▍", "▌", "▋", "▊", "▉", "█"}
+)
+
+// StyleSet is the set of colors used to draw a scrollbar.
+type StyleSet struct {
+	Track vaxis.Color
+	Thumb vaxis.Color
+	// Active is the color of the thumb while the mouse is over the scrollbar or dragging it.
+	Active vaxis.Color
+}
+
+// Scrollbar is a [vxfw.Widget] which draws a scrollbar for content of length Content, of which
+// Viewport is visible starting at Offset. Lengths are in whatever unit the content scrolls by,
+// like lines or items. The thumb is drawn with eighth blocks, so it moves smoothly even when the
+// content is much longer than the scrollbar.
+//
+// A scrollbar is one cell thick, and as long as the incoming constraints allow, or Viewport cells
+// long if they're unbounded. Put a vertical scrollbar in a [vxlayout.Row] next to the content it
+// scrolls:
+//
+//	vxlayout.Row([]vxfw.Widget{vxlayout.Expanded(content, 1), bar}, vxlayout.Options{
+//		CrossAxis: vxlayout.CrossAxisStretch,
+//	})
+//
+// Clicking the track moves the thumb to the click, dragging the thumb scrolls with the mouse, and
+// the mouse wheel scrolls by one unit. Each of these sets Offset and calls OnScroll. Nothing is
+// drawn but the track when all of the content fits in the viewport.
+type Scrollbar struct {
+	Orientation vxlayout.Orientation
+	Style       StyleSet
+
+	Content  int
+	Viewport int
+	Offset   int
+
+	// OnScroll is called with the new offset when the scrollbar is used to scroll.
+	OnScroll func(offset int) (vxfw.Command, error)
+
+	length   int
+	cells    []*cell
+	hover    bool
+	dragging bool
+	// The mouse position along the scrollbar and the offset when the drag started
+	dragFrom   int
+	dragOffset int
+}
+
+// New returns a [Scrollbar] with orientation, which calls onScroll when it's used to scroll.
+func New(orientation vxlayout.Orientation, onScroll func(offset int) (vxfw.Command, error)) *Scrollbar {
+	return &Scrollbar{
+		Orientation: orientation,
+		OnScroll:    onScroll,
+		Style: StyleSet{
+			Track:  vaxis.ColorDefault,
+			Thumb:  vaxis.IndexColor(8),
+			Active: vaxis.IndexColor(7),
+		},
+	}
+}
+
+// maxOffset returns the largest offset, where the end of the content is at the end of the viewport.
+func (s *Scrollbar) maxOffset() int {
+	if s.Content <= s.Viewport {
+		return 0
+	}
+	return s.Content - s.Viewport
+}
+
+// thumb returns the start and size of the thumb in a scrollbar length cells long, in eighths of a
+// cell. The thumb is at least one cell long. size is 0 if the content fits in the viewport.
+func (s *Scrollbar) thumb(length int) (start, size int) {
+	track := 8 * length
+	if s.Content <= s.Viewport || s.Viewport <= 0 || track == 0 {
+		return 0, 0
+	}
+
+	size = int(math.Round(float64(track) * float64(s.Viewport) / float64(s.Content)))
+	if size < 8 {
+		size = 8
+	}
+	if size > track {
+		size = track
+	}
+
+	offset := clamp(s.Offset, 0, s.maxOffset())
+	start = int(math.Round(float64(track-size) * float64(offset) / float64(s.maxOffset())))
+	return start, size
+}
+
+// ScrollTo sets Offset to offset, within the content, and calls OnScroll if it changed.
+func (s *Scrollbar) ScrollTo(offset int) (vxfw.Command, error) {
+	offset = clamp(offset, 0, s.maxOffset())
+	if offset == s.Offset {
+		return nil, nil
+	}
+	s.Offset = offset
+	if s.OnScroll == nil {
+		return vxfw.RedrawCmd{}, nil
+	}
+	cmd, err := s.OnScroll(offset)
+	if err != nil {
+		return nil, err
+	}
+	return []vxfw.Command{vxfw.RedrawCmd{}, cmd}, nil
+}
+
+// jump scrolls so the thumb is centered on the cell at pos.
+func (s *Scrollbar) jump(pos int) (vxfw.Command, error) {
+	_, size := s.thumb(s.length)
+	track := 8*s.length - size
+	if track <= 0 {
+		return nil, nil
+	}
+	center := 8*pos + 4 - size/2
+	return s.ScrollTo(int(math.Round(float64(center) * float64(s.maxOffset()) / float64(track))))
+}
+
+// drag scrolls by the distance the mouse has moved since the drag started.
+func (s *Scrollbar) drag(pos int) (vxfw.Command, error) {
+	_, size := s.thumb(s.length)
+	track := 8*s.length - size
+	if track <= 0 {
+		return nil, nil
+	}
+	delta := float64(8*(pos-s.dragFrom)) * float64(s.maxOffset()) / float64(track)
+	return s.ScrollTo(s.dragOffset + int(math.Round(delta)))
+}
+
+// position returns the position of mouse along the scrollbar, in screen coordinates.
+func (s *Scrollbar) position(mouse vaxis.Mouse) int {
+	if s.Orientation == vxlayout.Vertical {
+		return mouse.Row
+	}
+	return mouse.Col
+}
+
+var _ vxfw.Widget = &Scrollbar{}
+
+func (s *Scrollbar) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	length := int(s.Orientation.MainAxis(ctx.Max))
+	if length == math.MaxUint16 {
+		length = s.Viewport
+	}
+	s.length = length
+
+	size := s.Orientation.Size(uint16(length), 1)
+	surface := vxfw.NewSurface(size.Width, size.Height, s)
+
+	thumb := s.Style.Thumb
+	if s.hover || s.dragging {
+		thumb = s.Style.Active
+	}
+	blocks := lowerBlocks
+	if s.Orientation == vxlayout.Horizontal {
+		blocks = leftBlocks
+	}
+
+	start, end := s.thumb(length)
+	end += start
+	for i := 0; i < length; i++ {
+		// The part of the cell covered by the thumb, in eighths from the start of the cell
+		from := clamp(start-8*i, 0, 8)
+		to := clamp(end-8*i, 0, 8)
+
+		style := vaxis.Style{Foreground: thumb, Background: s.Style.Track}
+		filled := to - from
+		switch {
+		case filled == 0 || filled == 8:
+		case s.Orientation == vxlayout.Vertical && to == 8, s.Orientation == vxlayout.Horizontal && from == 0:
+			// Blocks fill from the bottom or the left, which is where the thumb is
+		default:
+			// The thumb is at the other end of the cell, so fill the rest of it in reverse
+			filled = 8 - filled
+			style.Attribute = vaxis.AttrReverse
+		}
+
+		grapheme := blocks[filled]
+		pos := s.Orientation.Size(uint16(i), 0)
+		surface.WriteCell(pos.Width, pos.Height, vaxis.Cell{
+			Character: vaxis.Character{Grapheme: grapheme, Width: 1},
+			Style:     style,
+		})
+	}
+
+	// Each cell has a transparent widget on top of it, so clicks know where they landed
+	for len(s.cells) < length {
+		s.cells = append(s.cells, &cell{bar: s, index: len(s.cells)})
+	}
+	for i := 0; i < length; i++ {
+		pos := s.Orientation.Size(uint16(i), 0)
+		surface.AddChild(int(pos.Width), int(pos.Height), vxfw.Surface{
+			Size:   vxfw.Size{Width: 1, Height: 1},
+			Widget: s.cells[i],
+		})
+	}
+
+	return surface, nil
+}
+
+func (s *Scrollbar) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		switch {
+		case ev.EventType == vaxis.EventMotion && ev.Button == vaxis.MouseLeftButton && s.dragging:
+			cmd, err := s.drag(s.position(ev))
+			return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+		case ev.EventType == vaxis.EventRelease, ev.EventType == vaxis.EventMotion && s.dragging:
+			// A motion without the button held means the release happened somewhere else
+			if s.dragging {
+				s.dragging = false
+				return vxfw.ConsumeAndRedraw(), nil
+			}
+		case ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseWheelUp:
+			cmd, err := s.ScrollTo(s.Offset - 1)
+			return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+		case ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseWheelDown:
+			cmd, err := s.ScrollTo(s.Offset + 1)
+			return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+		}
+	case vxfw.MouseEnter:
+		s.hover = true
+		return vxfw.RedrawCmd{}, nil
+	case vxfw.MouseLeave:
+		s.hover = false
+		return vxfw.RedrawCmd{}, nil
+	}
+	return nil, nil
+}
+
+// cell is the widget on top of a single cell of a scrollbar.
+type cell struct {
+	bar   *Scrollbar
+	index int
+}
+
+func (c *cell) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	return vxfw.Surface{Size: vxfw.Size{Width: 1, Height: 1}, Widget: c}, nil
+}
+
+func (c *cell) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok || mouse.EventType != vaxis.EventPress || mouse.Button != vaxis.MouseLeftButton {
+		return nil, nil
+	}
+
+	s := c.bar
+	start, size := s.thumb(s.length)
+	if size == 0 {
+		return nil, nil
+	}
+
+	// Clicking the track jumps there first, so the thumb can be dragged from where it lands
+	var cmd vxfw.Command
+	if center := 8*c.index + 4; center < start || center >= start+size {
+		var err error
+		if cmd, err = s.jump(c.index); err != nil {
+			return nil, err
+		}
+	}
+	s.dragging = true
+	s.dragFrom = s.position(mouse)
+	s.dragOffset = s.Offset
+	return []vxfw.Command{cmd, vxfw.ConsumeAndRedraw()}, nil
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
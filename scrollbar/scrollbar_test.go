@@ -0,0 +1,143 @@
+package scrollbar
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+func draw(t *testing.T, s *Scrollbar, length uint16) vxfw.Surface {
+	t.Helper()
+	max := s.Orientation.Size(length, 10)
+	surface, err := s.Draw(vxfw.DrawContext{Max: max, Characters: vaxis.Characters})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return surface
+}
+
+func TestScrollbarDraw(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation vxlayout.Orientation
+		offset      int
+		want        []string
+		reversed    []bool
+	}{
+		{
+			name:        "top",
+			orientation: vxlayout.Vertical,
+			offset:      0,
+			want:        []string{"█", "█", " ", " "},
+			reversed:    []bool{false, false, false, false},
+		},
+		{
+			name:        "part way",
+			orientation: vxlayout.Vertical,
+			offset:      1,
+			want:        []string{"▆", "█", "▆", " "},
+			reversed:    []bool{false, false, true, false},
+		},
+		{
+			name:        "horizontal",
+			orientation: vxlayout.Horizontal,
+			offset:      1,
+			want:        []string{"▎", "█", "▎", " "},
+			reversed:    []bool{true, false, false, false},
+		},
+		{
+			name:        "end",
+			orientation: vxlayout.Vertical,
+			offset:      20,
+			want:        []string{" ", " ", "█", "█"},
+			reversed:    []bool{false, false, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		// Half of the content is visible, so the thumb is half of the 4 cell track, and each
+		// unit of offset moves it 16/8, or 2, eighths
+		s := New(tt.orientation, nil)
+		s.Content, s.Viewport, s.Offset = 16, 8, tt.offset
+		surface := draw(t, s, 4)
+
+		for i, want := range tt.want {
+			got := surface.Buffer[i]
+			if got.Grapheme != want {
+				t.Logf("wrong %s grapheme at %d, got=%q, want=%q", tt.name, i, got.Grapheme, want)
+				t.Fail()
+			}
+			if reversed := got.Attribute&vaxis.AttrReverse != 0; reversed != tt.reversed[i] {
+				t.Logf("wrong %s reverse at %d, got=%v, want=%v", tt.name, i, reversed, tt.reversed[i])
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestScrollbarMouse(t *testing.T) {
+	var scrolled []int
+	s := New(vxlayout.Vertical, func(offset int) (vxfw.Command, error) {
+		scrolled = append(scrolled, offset)
+		return nil, nil
+	})
+	s.Content, s.Viewport = 100, 10
+	surface := draw(t, s, 10)
+	if len(surface.Children) != 10 {
+		t.Fatalf("wrong number of cells, got=%d, want=10", len(surface.Children))
+	}
+
+	press := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventPress, Row: 9}
+	motion := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventMotion}
+	release := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventRelease}
+
+	// Clicking the end of the track scrolls to the end
+	if _, err := s.cells[9].HandleEvent(press, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	if s.Offset != 90 {
+		t.Logf("wrong offset after clicking the track, got=%d, want=90", s.Offset)
+		t.Fail()
+	}
+
+	// Dragging the thumb back up by 3 cells scrolls back by 3/9 of the content
+	motion.Row = 6
+	if _, err := s.HandleEvent(motion, vxfw.BubblePhase); err != nil {
+		t.Fatal(err)
+	}
+	if s.Offset != 60 {
+		t.Logf("wrong offset after dragging, got=%d, want=60", s.Offset)
+		t.Fail()
+	}
+
+	// Motion after the release doesn't scroll
+	if _, err := s.HandleEvent(release, vxfw.BubblePhase); err != nil {
+		t.Fatal(err)
+	}
+	motion.Row = 0
+	if _, err := s.HandleEvent(motion, vxfw.BubblePhase); err != nil {
+		t.Fatal(err)
+	}
+	if s.Offset != 60 {
+		t.Logf("wrong offset after release, got=%d, want=60", s.Offset)
+		t.Fail()
+	}
+
+	wheel := vaxis.Mouse{Button: vaxis.MouseWheelDown, EventType: vaxis.EventPress}
+	if _, err := s.HandleEvent(wheel, vxfw.BubblePhase); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int{90, 60, 61}
+	if len(scrolled) != len(want) {
+		t.Fatalf("wrong scroll callbacks, got=%v, want=%v", scrolled, want)
+	}
+	for i := range want {
+		if scrolled[i] != want[i] {
+			t.Logf("wrong scroll callback %d, got=%d, want=%d", i, scrolled[i], want[i])
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package statusbar provides a [StatusBar] widget with left, center and right segments.
+package statusbar
+
+import (
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// Segment is a piece of text in a [StatusBar].
+type Segment struct {
+	Text  string
+	Style vaxis.Style
+
+	// When there is not enough space for all segments, segments with the lowest priority are
+	// dropped first.
+	Priority int
+}
+
+// Separators are drawn between segments. Left is used in the left and center groups, and Right in
+// the right group, so that glyphs can point towards the center of the bar.
+type Separators struct {
+	Left  string
+	Right string
+
+	// Powerline separators are colored to transition between the backgrounds of adjacent
+	// segments, and are also drawn at the inner end of the left and right groups. They work
+	// best when every segment and the bar itself have a background color.
+	Powerline bool
+}
+
+var (
+	// NoSeparators places segments next to each other.
+	NoSeparators = Separators{}
+	// Bars separates segments with a vertical line.
+	Bars = Separators{Left: " │ ", Right: " │ "}
+	// Powerline separates segments with solid arrows. It requires a font with powerline glyphs.
+	Powerline = Separators{Left: "", Right: "", Powerline: true}
+	// PowerlineThin separates segments with thin arrows. It requires a font with powerline
+	// glyphs.
+	PowerlineThin = Separators{Left: "", Right: ""}
+)
+
+// StatusBar is a [vxfw.Widget] which lays out groups of segments on the left, center and right of
+// a single row. The center group is centered in the bar regardless of the size of the other
+// groups.
+// If the segments don't fit, segments are dropped in order of ascending priority. Segments with
+// the same priority are dropped from the center of the bar outwards.
+type StatusBar struct {
+	Left   []Segment
+	Center []Segment
+	Right  []Segment
+
+	// Style is used for the space which isn't covered by a segment
+	Style      vaxis.Style
+	Separators Separators
+	// Padding is added to both sides of each segment
+	Padding uint16
+}
+
+// New returns an empty [StatusBar].
+func New() *StatusBar {
+	return &StatusBar{
+		Style:   vaxis.Style{Attribute: vaxis.AttrReverse},
+		Padding: 1,
+	}
+}
+
+// group identifies which group a segment belongs to.
+type group int
+
+const (
+	groupLeft group = iota
+	groupCenter
+	groupRight
+)
+
+// placed is a segment which is a candidate for drawing.
+type placed struct {
+	Segment
+	group group
+	// rank orders segments with the same priority, the lowest rank is dropped first
+	rank    int
+	dropped bool
+}
+
+var _ vxfw.Widget = &StatusBar{}
+
+func (b *StatusBar) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if ctx.Max.HasUnboundedWidth() {
+		panic("StatusBar must have a bounded width")
+	}
+
+	segments := b.segments()
+	width := ctx.Max.Width
+	measure := func(g group) uint16 {
+		return b.measure(ctx, segments, g)
+	}
+
+	// Drop segments until the center fits and the left and right groups each fit on their side
+	// of it.
+	for {
+		center := measure(groupCenter)
+		side := uint16(0)
+		if center <= width {
+			side = (width - center) / 2
+		}
+		if center <= width && measure(groupLeft) <= side && measure(groupRight) <= side {
+			break
+		}
+
+		drop := -1
+		for i, s := range segments {
+			if s.dropped {
+				continue
+			}
+			if drop < 0 || s.Priority < segments[drop].Priority ||
+				(s.Priority == segments[drop].Priority && s.rank < segments[drop].rank) {
+				drop = i
+			}
+		}
+		if drop < 0 {
+			break
+		}
+		segments[drop].dropped = true
+	}
+
+	left := vxlayout.Row(b.widgets(segments, groupLeft), vxlayout.Options{})
+	center := vxlayout.Row(b.widgets(segments, groupCenter), vxlayout.Options{})
+	right := vxlayout.Row(b.widgets(segments, groupRight), vxlayout.Options{MainAxis: vxlayout.MainAxisEnd})
+
+	// The sides are loosely flexible so that their segments are measured without a minimum width
+	children := []vxfw.Widget{vxlayout.Flexible(left, 1)}
+	if w := measure(groupCenter); w > 0 {
+		children = append(children, vxlayout.Constrained(center, nil, &vxfw.Size{Width: w}))
+	}
+	children = append(children, vxlayout.Flexible(right, 1))
+	row := vxlayout.Row(children, vxlayout.Options{})
+
+	s, err := row.Draw(ctx.WithConstraints(vxfw.Size{}, vxfw.Size{Width: width, Height: 1}))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	bar := vxfw.NewSurface(width, 1, b)
+	bar.FillStyle(b.Style)
+	bar.AddChild(0, 0, s)
+	return bar, nil
+}
+
+// segments returns the segments of each group, ranked so that segments closest to the center of
+// the bar are dropped first.
+func (b *StatusBar) segments() []placed {
+	out := make([]placed, 0, len(b.Left)+len(b.Center)+len(b.Right))
+	for i, s := range b.Left {
+		out = append(out, placed{Segment: s, group: groupLeft, rank: -i})
+	}
+	for i, s := range b.Center {
+		// Center segments are dropped before the sides
+		out = append(out, placed{Segment: s, group: groupCenter, rank: -len(b.Left) - len(b.Right) - i})
+	}
+	for i, s := range b.Right {
+		out = append(out, placed{Segment: s, group: groupRight, rank: i - len(b.Right) + 1})
+	}
+	return out
+}
+
+// visible returns the segments of g which haven't been dropped.
+func visible(segments []placed, g group) []Segment {
+	var out []Segment
+	for _, s := range segments {
+		if s.group == g && !s.dropped {
+			out = append(out, s.Segment)
+		}
+	}
+	return out
+}
+
+// measure returns the width of the visible segments of g, including separators.
+func (b *StatusBar) measure(ctx vxfw.DrawContext, segments []placed, g group) uint16 {
+	var width uint16
+	for _, item := range b.items(segments, g) {
+		for _, char := range ctx.Characters(item.text) {
+			width += uint16(char.Width)
+		}
+	}
+	return width
+}
+
+// widgets returns the widgets to draw the visible segments of g.
+func (b *StatusBar) widgets(segments []placed, g group) []vxfw.Widget {
+	items := b.items(segments, g)
+	out := make([]vxfw.Widget, len(items))
+	for i, item := range items {
+		t := text.New(item.text)
+		t.Softwrap = false
+		t.Style = item.style
+		out[i] = t
+	}
+	return out
+}
+
+// item is a segment or a separator.
+type item struct {
+	text  string
+	style vaxis.Style
+}
+
+// items returns the segments and separators of g in order.
+func (b *StatusBar) items(segments []placed, g group) []item {
+	visible := visible(segments, g)
+	if len(visible) == 0 {
+		return nil
+	}
+
+	pad := strings.Repeat(" ", int(b.Padding))
+	sep := b.Separators.Left
+	if g == groupRight {
+		sep = b.Separators.Right
+	}
+
+	var out []item
+	for i, s := range visible {
+		if g == groupRight && (i > 0 || b.Separators.Powerline) {
+			// Right separators come before their segment
+			prev := b.Style
+			if i > 0 {
+				prev = visible[i-1].Style
+			}
+			out = append(out, b.separator(sep, s.Style, prev))
+		}
+
+		out = append(out, item{text: pad + s.Text + pad, style: s.Style})
+
+		if g != groupRight && (i < len(visible)-1 || b.Separators.Powerline) {
+			next := b.Style
+			if i < len(visible)-1 {
+				next = visible[i+1].Style
+			}
+			out = append(out, b.separator(sep, s.Style, next))
+		}
+	}
+	return out
+}
+
+// separator returns the separator between the segment styled owner and its neighbor.
+func (b *StatusBar) separator(sep string, owner, neighbor vaxis.Style) item {
+	if !b.Separators.Powerline {
+		return item{text: sep, style: b.Style}
+	}
+
+	// Powerline glyphs are drawn in the background color of the segment they belong to, on top
+	// of the background of the neighbor they point towards.
+	return item{
+		text:  sep,
+		style: vaxis.Style{Foreground: owner.Background, Background: neighbor.Background},
+	}
+}
@@ -0,0 +1,66 @@
+package statusbar
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// content returns the graphemes of row 0 of the flattened surface s.
+func content(s vxfw.Surface) string {
+	cells := make([]string, s.Size.Width)
+	var flatten func(s vxfw.Surface, col int)
+	flatten = func(s vxfw.Surface, col int) {
+		for i, cell := range s.Buffer {
+			if i < int(s.Size.Width) && col+i < len(cells) && cell.Grapheme != "" {
+				cells[col+i] = cell.Grapheme
+			}
+		}
+		for _, child := range s.Children {
+			flatten(child.Surface, col+child.Origin.Col)
+		}
+	}
+	flatten(s, 0)
+
+	out := ""
+	for _, c := range cells {
+		if c == "" {
+			c = " "
+		}
+		out += c
+	}
+	return out
+}
+
+func TestStatusBar(t *testing.T) {
+	bar := New()
+	bar.Padding = 0
+	bar.Left = []Segment{{Text: "NORMAL", Priority: 2}, {Text: "main", Priority: 0}}
+	bar.Center = []Segment{{Text: "file.go", Priority: 1}}
+	bar.Right = []Segment{{Text: "utf-8", Priority: 0}, {Text: "1:1", Priority: 2}}
+	bar.Separators = Separators{Left: "|", Right: "|"}
+
+	tests := []struct {
+		width uint16
+		want  string
+	}{
+		{31, "NORMAL|main file.go   utf-8|1:1"},
+		// the lowest priority segments are dropped, the innermost first
+		{28, "NORMAL    file.go  utf-8|1:1"},
+		{20, "NORMALfile.go    1:1"},
+		{12, "NORMAL   1:1"},
+	}
+
+	for _, tt := range tests {
+		ctx := vxfw.DrawContext{Max: vxfw.Size{Width: tt.width, Height: 1}, Characters: vaxis.Characters}
+		s, err := bar.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := content(s); got != tt.want {
+			t.Logf("wrong content at width %d, got=%q, want=%q", tt.width, got, tt.want)
+			t.Fail()
+		}
+	}
+}
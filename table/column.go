@@ -0,0 +1,160 @@
+package table
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+type widthKind int
+
+const (
+	fit widthKind = iota
+	fixed
+	fraction
+	flex
+)
+
+// Width is how the width of a [Column] is computed. The zero value is [Fit].
+type Width struct {
+	kind   widthKind
+	n      uint16
+	factor float64
+}
+
+// Fixed returns a [Width] of n cells.
+func Fixed(n uint16) Width { return Width{kind: fixed, n: n} }
+
+// Fraction returns a [Width] of factor times the width of the table, like [vxlayout.Fraction].
+func Fraction(factor float64) Width { return Width{kind: fraction, factor: factor} }
+
+// Flex returns a [Width] which shares the space left over by the other columns with the other
+// flexible columns, in proportion to factor, like [vxlayout.Expanded].
+func Flex(factor uint16) Width { return Width{kind: flex, n: factor} }
+
+// Fit returns a [Width] which fits the title of the column and the cells in view.
+func Fit() Width { return Width{} }
+
+// Column describes a column of a [Table].
+type Column struct {
+	Title string
+	Width Width
+	// MinWidth is the narrowest the column can be resized to. A MinWidth of 0 is the same as 1.
+	MinWidth uint16
+	// Align positions the cells within the column. [vxlayout.AlignStretch] is the same as
+	// [vxlayout.AlignStart].
+	Align vxlayout.Alignment
+
+	// Sortable columns are sorted by clicking their header.
+	Sortable bool
+	// Less reports whether cell a sorts before cell b. If Less is nil, cells are compared with
+	// [Compare].
+	Less func(a, b string) bool
+}
+
+// Compare compares a and b as numbers if both are numbers, and otherwise as strings, ignoring
+// case. Numbers sort before strings, so a column mixing the two has a consistent order. It returns
+// -1, 0 or 1, like [strings.Compare].
+func Compare(a, b string) int {
+	x, y := newSortKey(a, true), newSortKey(b, true)
+	return x.compare(&y)
+}
+
+// sortKey is a cell prepared for [Compare], so a column can be sorted without parsing each cell
+// on every comparison.
+type sortKey struct {
+	s      string
+	lower  string
+	n      float64
+	number bool
+}
+
+// newSortKey returns the key of s. If parse is false, only s is kept.
+func newSortKey(s string, parse bool) sortKey {
+	k := sortKey{s: s}
+	if !parse {
+		return k
+	}
+	k.lower = strings.ToLower(s)
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	// NaN isn't ordered, so it sorts as a string
+	k.n, k.number = n, err == nil && !math.IsNaN(n)
+	return k
+}
+
+func (k *sortKey) compare(other *sortKey) int {
+	switch {
+	case k.number && !other.number:
+		return -1
+	case !k.number && other.number:
+		return 1
+	case k.number && other.number:
+		switch {
+		case k.n < other.n:
+			return -1
+		case k.n > other.n:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(k.lower, other.lower)
+}
+
+// layout returns the widths of columns in total cells, separated by gap. content is the width of
+// the content of each column, used for [Fit] columns. Columns are sized in order, so if they
+// don't all fit the last columns are narrowed, down to nothing.
+func layout(columns []Column, content []uint16, total, gap uint16) []uint16 {
+	widths := make([]uint16, len(columns))
+	if len(columns) == 0 {
+		return widths
+	}
+
+	available := total
+	if spacing := gap * uint16(len(columns)-1); spacing < available {
+		available -= spacing
+	} else {
+		available = 0
+	}
+
+	// Flexible columns get what the others leave
+	used := uint16(0)
+	var totalFlex uint16
+	for i, c := range columns {
+		var w uint16
+		switch c.Width.kind {
+		case fit:
+			w = content[i]
+		case fixed:
+			w = c.Width.n
+		case fraction:
+			factor := math.Max(0, math.Min(1, c.Width.factor))
+			w = uint16(math.Round(float64(available) * factor))
+		case flex:
+			totalFlex += c.Width.n
+			continue
+		}
+		if w > available-used {
+			w = available - used
+		}
+		widths[i] = w
+		used += w
+	}
+
+	if totalFlex == 0 {
+		return widths
+	}
+	remaining := available - used
+	last := -1
+	for i, c := range columns {
+		if c.Width.kind == flex && c.Width.n > 0 {
+			widths[i] = remaining * c.Width.n / totalFlex
+			used += widths[i]
+			last = i
+		}
+	}
+	// The last flexible column gets any space lost to rounding
+	widths[last] += available - used
+	return widths
+}
@@ -0,0 +1,206 @@
+package table
+
+import (
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// doubleClick is the longest time between two clicks of a double click.
+const doubleClick = 400 * time.Millisecond
+
+// Layout is the arrangement of the columns of a [Table], as changed by resizing and reordering
+// them. It can be encoded, for example as JSON, to restore the arrangement later.
+type Layout struct {
+	// Order is the indexes of the columns, in the order they're shown.
+	Order []int `json:"order"`
+	// Widths is the width of each column, by index. Columns which haven't been given a fixed
+	// width, by resizing them or with [Fixed], have a width of 0 and keep their [Width].
+	Widths []uint16 `json:"widths"`
+}
+
+// Layout returns the current arrangement of the columns.
+func (t *Table) Layout() Layout {
+	l := Layout{
+		Order:  append([]int{}, t.columnOrder()...),
+		Widths: make([]uint16, len(t.Columns)),
+	}
+	for i, col := range t.Columns {
+		if col.Width.kind == fixed {
+			l.Widths[i] = col.Width.n
+		}
+	}
+	return l
+}
+
+// SetLayout restores an arrangement of the columns returned by [Table.Layout]. Indexes of columns
+// which no longer exist are ignored, and columns missing from the layout are shown after the
+// others, so a layout saved before columns were added or removed can still be restored.
+func (t *Table) SetLayout(l Layout) {
+	seen := make([]bool, len(t.Columns))
+	order := make([]int, 0, len(t.Columns))
+	for _, i := range l.Order {
+		if i >= 0 && i < len(t.Columns) && !seen[i] {
+			order = append(order, i)
+			seen[i] = true
+		}
+	}
+	for i, ok := range seen {
+		if !ok {
+			order = append(order, i)
+		}
+	}
+	t.columns = order
+
+	for i, w := range l.Widths {
+		if i < len(t.Columns) && w > 0 {
+			t.ResizeColumn(i, w)
+		}
+	}
+}
+
+// columnOrder returns the indexes of the columns in the order they're shown.
+func (t *Table) columnOrder() []int {
+	if len(t.columns) != len(t.Columns) {
+		// Columns were added or removed, so start over
+		t.columns = make([]int, len(t.Columns))
+		for i := range t.columns {
+			t.columns[i] = i
+		}
+	}
+	return t.columns
+}
+
+// position returns the position that col is shown at.
+func (t *Table) position(col int) int {
+	for pos, i := range t.columnOrder() {
+		if i == col {
+			return pos
+		}
+	}
+	return -1
+}
+
+// MoveColumn moves col to be shown at pos. Columns keep their index in Columns, which is also
+// their index in Data, wherever they're shown.
+func (t *Table) MoveColumn(col, pos int) {
+	from := t.position(col)
+	order := t.columnOrder()
+	if from < 0 || pos < 0 || pos >= len(order) || from == pos {
+		return
+	}
+	if from < pos {
+		copy(order[from:pos], order[from+1:pos+1])
+	} else {
+		copy(order[pos+1:from+1], order[pos:from])
+	}
+	order[pos] = col
+}
+
+// ResizeColumn gives col a [Fixed] width, which is at least its MinWidth.
+func (t *Table) ResizeColumn(col int, width uint16) {
+	if col < 0 || col >= len(t.Columns) {
+		return
+	}
+	min := t.Columns[col].MinWidth
+	if min == 0 {
+		min = 1
+	}
+	if width < min {
+		width = min
+	}
+	t.Columns[col].Width = Fixed(width)
+}
+
+// FitColumn resizes col to fit its title and the cells which were in view when the table was last
+// drawn.
+func (t *Table) FitColumn(col int) {
+	if col < 0 || col >= len(t.content) {
+		return
+	}
+	t.ResizeColumn(col, t.content[col])
+}
+
+// drag is the state of a column being resized or moved with the mouse.
+type drag struct {
+	// The column being resized, and where the mouse and the border were when it started
+	resizing int
+	from     int
+	width    uint16
+
+	// The column being moved, and whether it has moved yet
+	moving int
+	moved  bool
+
+	// The last click on a border, to detect double clicks
+	lastClick time.Time
+	lastCol   int
+}
+
+// handleDrag handles mouse events while a column is being resized or moved. It returns nil if ev
+// isn't part of a drag.
+func (t *Table) handleDrag(ev vaxis.Mouse) vxfw.Command {
+	d := &t.drag
+	if d.resizing < 0 && d.moving < 0 {
+		return nil
+	}
+
+	if ev.EventType == vaxis.EventMotion && ev.Button == vaxis.MouseLeftButton {
+		if d.resizing < 0 {
+			// Moves happen when the header is dragged over another header
+			return nil
+		}
+		width := int(d.width) + ev.Col - d.from
+		if width < 0 {
+			width = 0
+		}
+		t.ResizeColumn(d.resizing, uint16(width))
+		return vxfw.ConsumeAndRedraw()
+	}
+
+	// The drag is over when the button is released, or if the mouse moves without it, because it
+	// was released somewhere the table didn't see
+	if ev.EventType == vaxis.EventRelease || ev.EventType == vaxis.EventMotion {
+		d.resizing, d.moving = -1, -1
+		return vxfw.ConsumeAndRedraw()
+	}
+	return nil
+}
+
+// borderSlot is the widget on the border after a column header, which resizes the column when
+// dragged.
+type borderSlot struct {
+	table *Table
+	col   int
+}
+
+func (b *borderSlot) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	return vxfw.Surface{Size: vxfw.Size{Width: 1, Height: 1}, Widget: b}, nil
+}
+
+func (b *borderSlot) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	t := b.table
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		if ev.EventType != vaxis.EventPress || ev.Button != vaxis.MouseLeftButton || b.col >= len(t.widths) {
+			return nil, nil
+		}
+
+		d := &t.drag
+		now := time.Now()
+		if d.lastCol == b.col && now.Sub(d.lastClick) < doubleClick {
+			d.lastClick = time.Time{}
+			t.FitColumn(b.col)
+			return vxfw.ConsumeAndRedraw(), nil
+		}
+		d.lastClick, d.lastCol = now, b.col
+		d.resizing, d.from, d.width = b.col, ev.Col, t.widths[b.col]
+		return vxfw.ConsumeEventCmd{}, nil
+	case vxfw.MouseEnter:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeResizeHorizontal), nil
+	case vxfw.MouseLeave:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,682 @@
+// Package table provides a [Table] widget: rows of cells under a header, with sortable columns
+// and row selection.
+package table
+
+import (
+	"sort"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// Data is the content of a [Table]. Cells are only requested for the rows in view, and to sort the
+// table, so Data can be backed by anything that can look up a row by its index.
+type Data interface {
+	// Len returns the number of rows.
+	Len() int
+	// Cell returns the text of the cell in row and col.
+	Cell(row, col int) string
+}
+
+// Rows is [Data] held in memory as rows of cells. Missing cells are empty.
+type Rows [][]string
+
+func (r Rows) Len() int { return len(r) }
+
+func (r Rows) Cell(row, col int) string {
+	if col >= len(r[row]) {
+		return ""
+	}
+	return r[row][col]
+}
+
+// SelectionMode is how rows of a [Table] can be selected.
+type SelectionMode int
+
+const (
+	// SelectNone doesn't select rows. The cursor can still be moved.
+	SelectNone SelectionMode = iota
+	// SelectSingle selects the row under the cursor.
+	SelectSingle
+	// SelectMultiple selects any number of rows. Space toggles the row under the cursor, and
+	// Shift with the arrow keys or a click selects a range.
+	SelectMultiple
+)
+
+// StyleSet is the set of styles used to draw a table.
+type StyleSet struct {
+	Header   vaxis.Style
+	Row      vaxis.Style
+	Selected vaxis.Style
+	Cursor   vaxis.Style
+}
+
+// Table is a stateful [vxfw.Widget] which shows [Data] in columns under a header. Only the rows
+// in view are drawn, so tables of any length are fast to draw and scroll.
+//
+// Rows are identified by their index in Data, which doesn't change when the table is sorted.
+// Positions in the sorted table, as used by the cursor, are called views.
+//
+// When Table has focus, the following keys are handled:
+//
+//	Up, k / Down, j        move the cursor
+//	PgUp / PgDown          move the cursor by a page
+//	Home, g / End, G       move the cursor to the first or last row
+//	Shift+Up / Shift+Down  select a range (SelectMultiple)
+//	Space                  toggle the selection of the row under the cursor (SelectMultiple)
+//	Ctrl+A                 select all rows (SelectMultiple)
+//	Enter                  call OnActivate
+//
+// Clicking a row moves the cursor to it, Ctrl+click toggles it and Shift+click selects a range.
+// Clicking the header of a sortable column sorts by it, first ascending, then descending, and
+// then not at all.
+//
+// If the table is Resizable, columns are resized by dragging the border after their header, and
+// fit to their content by double clicking it. If it's Reorderable, columns are moved by dragging
+// their header. The arrangement of the columns can be saved with [Table.Layout] and restored
+// with [Table.SetLayout].
+type Table struct {
+	Columns []Column
+	Data    Data
+	Style   StyleSet
+	Mode    SelectionMode
+	// Gap is the number of cells between columns.
+	Gap uint16
+
+	Resizable   bool
+	Reorderable bool
+
+	// OnSelect is called with the selected rows when the selection changes.
+	OnSelect func(rows []int) (vxfw.Command, error)
+	// OnActivate is called with the row under the cursor when Enter is pressed.
+	OnActivate func(row int) (vxfw.Command, error)
+
+	order    []int
+	sortBy   int
+	sortDesc bool
+
+	cursor   int
+	anchor   int
+	offset   int
+	visible  int
+	selected map[int]bool
+
+	// The indexes of Columns in the order they're shown, and the width of the content and the
+	// width of each column when the table was last drawn
+	columns []int
+	content []uint16
+	widths  []uint16
+	drag    drag
+
+	rows    []*rowSlot
+	headers []*headerSlot
+	borders []*borderSlot
+}
+
+// New returns a [Table] of data with columns.
+func New(columns []Column, data Data) *Table {
+	return &Table{
+		Columns: columns,
+		Data:    data,
+		Gap:     1,
+		Style: StyleSet{
+			Header:   vaxis.Style{Attribute: vaxis.AttrBold, UnderlineStyle: vaxis.UnderlineSingle},
+			Selected: vaxis.Style{Attribute: vaxis.AttrReverse},
+			Cursor:   vaxis.Style{Background: vaxis.IndexColor(8)},
+		},
+		sortBy:   -1,
+		selected: make(map[int]bool),
+		drag:     drag{resizing: -1, moving: -1},
+	}
+}
+
+// Len returns the number of rows.
+func (t *Table) Len() int {
+	t.sync()
+	return len(t.order)
+}
+
+// Row returns the row at view, or -1 if view is out of range.
+func (t *Table) Row(view int) int {
+	t.sync()
+	if view < 0 || view >= len(t.order) {
+		return -1
+	}
+	return t.order[view]
+}
+
+// Cursor returns the view of the cursor.
+func (t *Table) Cursor() int { return t.cursor }
+
+// Offset returns the view of the first row in view.
+func (t *Table) Offset() int { return t.offset }
+
+// Visible returns the number of rows which were in view when t was last drawn.
+func (t *Table) Visible() int { return t.visible }
+
+// SetOffset scrolls the table so view is the first row in view. The cursor is moved into view if
+// it isn't, so the table can be scrolled with a scrollbar.
+func (t *Table) SetOffset(view int) {
+	t.offset = clamp(view, 0, t.Len()-t.visible)
+	switch {
+	case t.cursor < t.offset:
+		t.cursor = t.offset
+	case t.visible > 0 && t.cursor >= t.offset+t.visible:
+		t.cursor = t.offset + t.visible - 1
+	}
+}
+
+// SetCursor moves the cursor to view, and selects its row if Mode is [SelectSingle].
+func (t *Table) SetCursor(view int) (vxfw.Command, error) {
+	return t.moveCursor(view, false)
+}
+
+// Selected returns the selected rows, in increasing order.
+func (t *Table) Selected() []int {
+	rows := make([]int, 0, len(t.selected))
+	for row := range t.selected {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	return rows
+}
+
+// IsSelected reports whether row is selected.
+func (t *Table) IsSelected(row int) bool { return t.selected[row] }
+
+// SetSelected replaces the selection with rows.
+func (t *Table) SetSelected(rows ...int) (vxfw.Command, error) {
+	t.selected = make(map[int]bool, len(rows))
+	for _, row := range rows {
+		t.selected[row] = true
+	}
+	return t.selectionChanged()
+}
+
+// Sort sorts the table by col, or unsorts it if col is -1. The cursor stays on the same row.
+func (t *Table) Sort(col int, descending bool) {
+	row := t.Row(t.cursor)
+	t.sortBy, t.sortDesc = col, descending
+	t.sortRows()
+	for view, r := range t.order {
+		if r == row {
+			t.cursor = view
+			break
+		}
+	}
+}
+
+// SortedBy returns the column the table is sorted by, or -1, and whether it's descending.
+func (t *Table) SortedBy() (col int, descending bool) { return t.sortBy, t.sortDesc }
+
+// Refresh sorts the table again, after the content of Data changes. Changes in the number of rows
+// are picked up when the table is drawn.
+func (t *Table) Refresh() {
+	t.order = nil
+	t.sync()
+}
+
+// sync rebuilds the order of the rows if the number of rows changed.
+func (t *Table) sync() {
+	n := 0
+	if t.Data != nil {
+		n = t.Data.Len()
+	}
+	if len(t.order) == n && t.order != nil {
+		return
+	}
+	t.sortRows()
+	for row := range t.selected {
+		if row >= n {
+			delete(t.selected, row)
+		}
+	}
+	t.cursor = clamp(t.cursor, 0, n-1)
+}
+
+func (t *Table) sortRows() {
+	n := 0
+	if t.Data != nil {
+		n = t.Data.Len()
+	}
+	t.order = make([]int, n)
+	for i := range t.order {
+		t.order[i] = i
+	}
+	if t.sortBy < 0 || t.sortBy >= len(t.Columns) {
+		return
+	}
+
+	col := &t.Columns[t.sortBy]
+	// Fetch the cells up front, so Data is asked for each cell once rather than on each
+	// comparison
+	keys := make([]sortKey, n)
+	for i := range keys {
+		keys[i] = newSortKey(t.Data.Cell(i, t.sortBy), col.Less == nil)
+	}
+	less := func(a, b *sortKey) bool {
+		if col.Less != nil {
+			return col.Less(a.s, b.s)
+		}
+		return a.compare(b) < 0
+	}
+	sort.SliceStable(t.order, func(i, j int) bool {
+		a, b := &keys[t.order[i]], &keys[t.order[j]]
+		if t.sortDesc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+// moveCursor moves the cursor to view. If extend is true and Mode is [SelectMultiple], the rows
+// from the anchor to the cursor are selected.
+func (t *Table) moveCursor(view int, extend bool) (vxfw.Command, error) {
+	n := t.Len()
+	if n == 0 {
+		return nil, nil
+	}
+	t.cursor = clamp(view, 0, n-1)
+
+	switch {
+	case t.Mode == SelectSingle:
+		return t.SetSelected(t.order[t.cursor])
+	case t.Mode == SelectMultiple && extend:
+		from, to := t.anchor, t.cursor
+		if from > to {
+			from, to = to, from
+		}
+		rows := make([]int, 0, to-from+1)
+		for v := from; v <= to; v++ {
+			rows = append(rows, t.order[v])
+		}
+		return t.SetSelected(rows...)
+	}
+	t.anchor = t.cursor
+	return vxfw.RedrawCmd{}, nil
+}
+
+// toggle toggles the selection of the row at view.
+func (t *Table) toggle(view int) (vxfw.Command, error) {
+	row := t.Row(view)
+	if row < 0 {
+		return nil, nil
+	}
+	if t.selected[row] {
+		delete(t.selected, row)
+	} else {
+		t.selected[row] = true
+	}
+	t.cursor, t.anchor = view, view
+	return t.selectionChanged()
+}
+
+func (t *Table) selectionChanged() (vxfw.Command, error) {
+	if t.OnSelect == nil {
+		return vxfw.RedrawCmd{}, nil
+	}
+	cmd, err := t.OnSelect(t.Selected())
+	if err != nil {
+		return nil, err
+	}
+	return []vxfw.Command{vxfw.RedrawCmd{}, cmd}, nil
+}
+
+// clickHeader sorts by col, cycling through ascending, descending and unsorted.
+func (t *Table) clickHeader(col int) {
+	if !t.Columns[col].Sortable {
+		return
+	}
+	switch {
+	case t.sortBy != col:
+		t.Sort(col, false)
+	case !t.sortDesc:
+		t.Sort(col, true)
+	default:
+		t.Sort(-1, false)
+	}
+}
+
+func (t *Table) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Key:
+		if ev.EventType == vaxis.EventRelease {
+			return nil, nil
+		}
+		return t.handleKey(ev)
+	case vaxis.Mouse:
+		if cmd := t.handleDrag(ev); cmd != nil {
+			return cmd, nil
+		}
+		if ev.EventType != vaxis.EventPress {
+			return nil, nil
+		}
+		switch ev.Button {
+		case vaxis.MouseWheelUp:
+			t.SetOffset(t.offset - 3)
+			return vxfw.ConsumeAndRedraw(), nil
+		case vaxis.MouseWheelDown:
+			t.SetOffset(t.offset + 3)
+			return vxfw.ConsumeAndRedraw(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *Table) handleKey(key vaxis.Key) (vxfw.Command, error) {
+	page := t.visible
+	if page < 1 {
+		page = 1
+	}
+	multiple := t.Mode == SelectMultiple
+
+	var cmd vxfw.Command
+	var err error
+	switch {
+	case key.Matches(vaxis.KeyUp), key.Matches('k'):
+		cmd, err = t.moveCursor(t.cursor-1, false)
+	case key.Matches(vaxis.KeyDown), key.Matches('j'):
+		cmd, err = t.moveCursor(t.cursor+1, false)
+	case key.Matches(vaxis.KeyUp, vaxis.ModShift) && multiple:
+		cmd, err = t.moveCursor(t.cursor-1, true)
+	case key.Matches(vaxis.KeyDown, vaxis.ModShift) && multiple:
+		cmd, err = t.moveCursor(t.cursor+1, true)
+	case key.Matches(vaxis.KeyPgUp):
+		cmd, err = t.moveCursor(t.cursor-page, false)
+	case key.Matches(vaxis.KeyPgDown):
+		cmd, err = t.moveCursor(t.cursor+page, false)
+	case key.Matches(vaxis.KeyHome), key.Matches('g'):
+		cmd, err = t.moveCursor(0, false)
+	case key.Matches(vaxis.KeyEnd), key.Matches('G'):
+		cmd, err = t.moveCursor(t.Len()-1, false)
+	case key.Matches(vaxis.KeySpace) && multiple:
+		cmd, err = t.toggle(t.cursor)
+	case key.Matches('a', vaxis.ModCtrl) && multiple:
+		cmd, err = t.SetSelected(t.order...)
+	case key.Matches(vaxis.KeyEnter):
+		row := t.Row(t.cursor)
+		if t.OnActivate == nil || row < 0 {
+			return nil, nil
+		}
+		cmd, err = t.OnActivate(row)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []vxfw.Command{cmd, vxfw.ConsumeAndRedraw()}, nil
+}
+
+var _ vxfw.Widget = &Table{}
+
+func (t *Table) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if ctx.Max.HasUnboundedHeight() || ctx.Max.HasUnboundedWidth() {
+		panic("Table must have bounded constraints")
+	}
+	t.sync()
+
+	width, height := ctx.Max.Width, ctx.Max.Height
+	s := vxfw.NewSurface(width, height, t)
+	if height == 0 {
+		return s, nil
+	}
+
+	// Keep the cursor in view
+	t.visible = int(height) - 1
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.visible > 0 && t.cursor >= t.offset+t.visible {
+		t.offset = t.cursor - t.visible + 1
+	}
+	t.offset = clamp(t.offset, 0, len(t.order)-t.visible)
+	end := t.offset + t.visible
+	if end > len(t.order) {
+		end = len(t.order)
+	}
+
+	// Fetch the cells in view once, and measure every column so it can be fit to its content
+	cells := make([][]string, end-t.offset)
+	for view := t.offset; view < end; view++ {
+		cells[view-t.offset] = make([]string, len(t.Columns))
+		for i := range t.Columns {
+			cells[view-t.offset][i] = t.Data.Cell(t.order[view], i)
+		}
+	}
+	titles := make([][]vaxis.Character, len(t.Columns))
+	t.content = make([]uint16, len(t.Columns))
+	for i := range t.Columns {
+		titles[i] = ctx.Characters(t.title(i))
+		t.content[i] = measure(titles[i])
+		for _, row := range cells {
+			if w := measure(ctx.Characters(row[i])); w > t.content[i] {
+				t.content[i] = w
+			}
+		}
+	}
+
+	// Columns are laid out in the order they're shown
+	order := t.columnOrder()
+	shown := make([]Column, len(order))
+	content := make([]uint16, len(order))
+	for pos, i := range order {
+		shown[pos], content[pos] = t.Columns[i], t.content[i]
+	}
+	widths := layout(shown, content, width, t.Gap)
+	t.widths = make([]uint16, len(t.Columns))
+	for pos, i := range order {
+		t.widths[i] = widths[pos]
+	}
+
+	// The header
+	for col := uint16(0); col < width; col++ {
+		s.WriteCell(col, 0, vaxis.Cell{Character: vaxis.Character{Grapheme: " ", Width: 1}, Style: t.Style.Header})
+	}
+	for len(t.headers) < len(t.Columns) {
+		t.headers = append(t.headers, &headerSlot{table: t, col: len(t.headers)})
+		t.borders = append(t.borders, &borderSlot{table: t, col: len(t.borders)})
+	}
+	x := uint16(0)
+	for pos, i := range order {
+		writeCell(&s, x, 0, widths[pos], titles[i], vxlayout.AlignStart, t.Style.Header)
+		s.AddChild(int(x), 0, vxfw.Surface{Size: vxfw.Size{Width: widths[pos], Height: 1}, Widget: t.headers[i]})
+		x += widths[pos]
+		if t.Resizable {
+			// The handle is in the gap after the column, or on its last cell if there's
+			// no gap
+			handle := x
+			if t.Gap == 0 && handle > 0 {
+				handle--
+			}
+			if handle < width {
+				s.AddChild(int(handle), 0, vxfw.Surface{Size: vxfw.Size{Width: 1, Height: 1}, Widget: t.borders[i]})
+			}
+		}
+		x += t.Gap
+	}
+
+	// The rows in view
+	for len(t.rows) < t.visible {
+		t.rows = append(t.rows, &rowSlot{table: t})
+	}
+	for view := t.offset; view < end; view++ {
+		row := t.order[view]
+		y := uint16(view-t.offset) + 1
+
+		style := t.Style.Row
+		switch {
+		case view == t.cursor:
+			style = t.Style.Cursor
+		case t.selected[row]:
+			style = t.Style.Selected
+		}
+		if view == t.cursor && t.selected[row] {
+			// Show both, by putting the selection's attributes on the cursor's colors
+			style.Attribute |= t.Style.Selected.Attribute
+		}
+
+		for col := uint16(0); col < width; col++ {
+			s.WriteCell(col, y, vaxis.Cell{Character: vaxis.Character{Grapheme: " ", Width: 1}, Style: style})
+		}
+		x := uint16(0)
+		for pos, i := range order {
+			chars := ctx.Characters(cells[view-t.offset][i])
+			writeCell(&s, x, y, widths[pos], chars, t.Columns[i].Align, style)
+			x += widths[pos] + t.Gap
+		}
+
+		slot := t.rows[view-t.offset]
+		slot.view = view
+		s.AddChild(0, int(y), vxfw.Surface{Size: vxfw.Size{Width: width, Height: 1}, Widget: slot})
+	}
+
+	return s, nil
+}
+
+// title returns the title of col, with an arrow if the table is sorted by it.
+func (t *Table) title(col int) string {
+	title := t.Columns[col].Title
+	if col != t.sortBy {
+		return title
+	}
+	if t.sortDesc {
+		return title + " ▼"
+	}
+	return title + " ▲"
+}
+
+// measure returns the width of chars.
+func measure(chars []vaxis.Character) uint16 {
+	var w uint16
+	for _, char := range chars {
+		w += uint16(char.Width)
+	}
+	return w
+}
+
+// writeCell writes chars into the width cells at col and row of s, aligned with align. If chars
+// don't fit they're truncated with an ellipsis.
+func writeCell(s *vxfw.Surface, col, row, width uint16, chars []vaxis.Character, align vxlayout.Alignment, style vaxis.Style) {
+	if width == 0 {
+		return
+	}
+
+	w := measure(chars)
+	if w > width {
+		// Keep as much as fits alongside the ellipsis
+		var kept uint16
+		n := 0
+		for ; n < len(chars) && kept+uint16(chars[n].Width) <= width-1; n++ {
+			kept += uint16(chars[n].Width)
+		}
+		chars = append(chars[:n:n], vaxis.Character{Grapheme: "…", Width: 1})
+		w = kept + 1
+	}
+
+	switch align {
+	case vxlayout.AlignCenter:
+		col += (width - w) / 2
+	case vxlayout.AlignEnd:
+		col += width - w
+	}
+	for _, char := range chars {
+		s.WriteCell(col, row, vaxis.Cell{Character: char, Style: style})
+		col += uint16(char.Width)
+	}
+}
+
+// rowSlot is the widget on top of a row in view, which handles clicks on it.
+type rowSlot struct {
+	table *Table
+	view  int
+}
+
+func (r *rowSlot) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	return vxfw.Surface{Size: vxfw.Size{Width: ctx.Max.Width, Height: 1}, Widget: r}, nil
+}
+
+func (r *rowSlot) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok || mouse.EventType != vaxis.EventPress || mouse.Button != vaxis.MouseLeftButton {
+		return nil, nil
+	}
+
+	t := r.table
+	var cmd vxfw.Command
+	var err error
+	switch {
+	case t.Mode == SelectMultiple && mouse.Modifiers&vaxis.ModCtrl != 0:
+		cmd, err = t.toggle(r.view)
+	case t.Mode == SelectMultiple && mouse.Modifiers&vaxis.ModShift != 0:
+		cmd, err = t.moveCursor(r.view, true)
+	case t.Mode == SelectMultiple:
+		t.cursor, t.anchor = r.view, r.view
+		cmd, err = t.SetSelected(t.Row(r.view))
+	default:
+		cmd, err = t.moveCursor(r.view, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []vxfw.Command{cmd, vxfw.FocusWidgetCmd(t), vxfw.ConsumeAndRedraw()}, nil
+}
+
+// headerSlot is the widget on top of a column header, which sorts the table when clicked.
+type headerSlot struct {
+	table *Table
+	col   int
+}
+
+func (h *headerSlot) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	return vxfw.Surface{Size: vxfw.Size{Width: ctx.Max.Width, Height: 1}, Widget: h}, nil
+}
+
+func (h *headerSlot) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	t := h.table
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		if h.col >= len(t.Columns) {
+			return nil, nil
+		}
+		sortable := t.Columns[h.col].Sortable
+		switch {
+		case ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseLeftButton && t.Reorderable:
+			// Sorting waits for the release, so dragging the header doesn't sort
+			t.drag.moving, t.drag.moved = h.col, false
+			return vxfw.ConsumeEventCmd{}, nil
+		case ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseLeftButton && sortable:
+			t.clickHeader(h.col)
+			return vxfw.ConsumeAndRedraw(), nil
+		case ev.EventType == vaxis.EventMotion && ev.Button == vaxis.MouseLeftButton && t.drag.moving >= 0 && t.drag.moving != h.col:
+			// Another header is being dragged over this one, swap places with it
+			t.MoveColumn(t.drag.moving, t.position(h.col))
+			t.drag.moved = true
+			return vxfw.ConsumeAndRedraw(), nil
+		case ev.EventType == vaxis.EventRelease && t.drag.moving == h.col && !t.drag.moved:
+			t.drag.moving = -1
+			if sortable {
+				t.clickHeader(h.col)
+				return vxfw.ConsumeAndRedraw(), nil
+			}
+		}
+	case vxfw.MouseEnter:
+		if h.col < len(h.table.Columns) && h.table.Columns[h.col].Sortable {
+			return vxfw.SetMouseShapeCmd(vaxis.MouseShapeClickable), nil
+		}
+	case vxfw.MouseLeave:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault), nil
+	}
+	return nil, nil
+}
+
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
@@ -0,0 +1,321 @@
+package table
+
+import (
+	"strconv"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// rows returns the graphemes of each row of s, with "_" for cells without a grapheme.
+func rows(s vxfw.Surface) []string {
+	out := make([]string, s.Size.Height)
+	for i, cell := range s.Buffer {
+		g := cell.Grapheme
+		if g == "" {
+			g = "_"
+		}
+		out[i/int(s.Size.Width)] += g
+	}
+	return out
+}
+
+func draw(t *testing.T, table *Table, width, height uint16) vxfw.Surface {
+	t.Helper()
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: width, Height: height}, Characters: vaxis.Characters}
+	s, err := table.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLayout(t *testing.T) {
+	columns := []Column{
+		{Width: Fixed(4)},
+		{Width: Fit()},
+		{Width: Fraction(0.25)},
+		{Width: Flex(1)},
+		{Width: Flex(2)},
+	}
+	// 4 gaps of 1 leave 20 columns: 4 fixed, 3 to fit, 5 for a quarter, and 8 shared 1:2
+	got := layout(columns, []uint16{0, 3, 0, 0, 0}, 24, 1)
+	want := []uint16{4, 3, 5, 2, 6}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong width of column %d, got=%d, want=%d", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+
+	// Columns that don't fit are narrowed, last first
+	got = layout(columns[:3], []uint16{0, 10, 0}, 12, 1)
+	want = []uint16{4, 6, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong narrowed width of column %d, got=%d, want=%d", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+}
+
+func TestTableDraw(t *testing.T) {
+	table := New([]Column{
+		{Title: "Name", Width: Fixed(6), Sortable: true},
+		{Title: "Size", Width: Fit(), Align: vxlayout.AlignEnd, Sortable: true},
+	}, Rows{
+		{"banana", "10"},
+		{"apple pie", "2"},
+		{"cherry", "300"},
+	})
+
+	got := rows(draw(t, table, 12, 4))
+	want := []string{
+		"Name   Size ",
+		"banana   10 ",
+		"apple…    2 ",
+		"cherry  300 ",
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong row %d, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+
+	// Clicking the header sorts, numerically, and marks the column
+	click := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventPress}
+	if _, err := table.headers[1].HandleEvent(click, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	got = rows(draw(t, table, 13, 4))
+	want = []string{
+		"Name   Size ▲",
+		"apple…      2",
+		"banana     10",
+		"cherry    300",
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong sorted row %d, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+
+	// The cursor stays on the same row
+	if table.Row(table.Cursor()) != 0 {
+		t.Logf("wrong cursor row after sorting, got=%d, want=0", table.Row(table.Cursor()))
+		t.Fail()
+	}
+
+	// Then descending, then unsorted
+	for _, want := range [][]int{{2, 0, 1}, {0, 1, 2}} {
+		if _, err := table.headers[1].HandleEvent(click, vxfw.TargetPhase); err != nil {
+			t.Fatal(err)
+		}
+		for view, row := range want {
+			if got := table.Row(view); got != row {
+				t.Logf("wrong row at %d, got=%d, want=%d", view, got, row)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestTableSelection(t *testing.T) {
+	var selected []int
+	table := New([]Column{{Title: "n"}}, Rows{{"a"}, {"b"}, {"c"}, {"d"}})
+	table.Mode = SelectMultiple
+	table.OnSelect = func(rows []int) (vxfw.Command, error) {
+		selected = rows
+		return nil, nil
+	}
+	draw(t, table, 10, 5)
+
+	keys := []vaxis.Key{
+		{Keycode: vaxis.KeyDown},
+		{Keycode: vaxis.KeyDown, Modifiers: vaxis.ModShift},
+		{Keycode: vaxis.KeyDown, Modifiers: vaxis.ModShift},
+	}
+	for _, key := range keys {
+		if _, err := table.HandleEvent(key, vxfw.TargetPhase); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(selected) != 3 || selected[0] != 1 || selected[2] != 3 {
+		t.Logf("wrong range selection, got=%v, want=[1 2 3]", selected)
+		t.Fail()
+	}
+
+	// Ctrl+click toggles a row without changing the rest of the selection
+	click := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventPress, Modifiers: vaxis.ModCtrl}
+	if _, err := table.rows[2].HandleEvent(click, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[0] != 1 || selected[1] != 3 {
+		t.Logf("wrong selection after ctrl+click, got=%v, want=[1 3]", selected)
+		t.Fail()
+	}
+}
+
+// counter is Data which counts the cells requested.
+type counter struct {
+	len   int
+	cells int
+}
+
+func (c *counter) Len() int { return c.len }
+
+func (c *counter) Cell(row, col int) string {
+	c.cells++
+	return strconv.Itoa(row)
+}
+
+func TestTableVirtualized(t *testing.T) {
+	data := &counter{len: 100000}
+	table := New([]Column{{Title: "n", Width: Flex(1)}}, data)
+
+	if _, err := table.SetCursor(50000); err != nil {
+		t.Fatal(err)
+	}
+	got := rows(draw(t, table, 6, 4))
+	want := []string{"n     ", "49998 ", "49999 ", "50000 "}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong row %d, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+	if data.cells != 3 {
+		t.Logf("wrong number of cells requested, got=%d, want=3", data.cells)
+		t.Fail()
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	columns := func() []Column {
+		return []Column{
+			{Title: "a", Width: Fixed(3), MinWidth: 2, Sortable: true},
+			{Title: "b", Width: Fixed(3)},
+			{Title: "c", Width: Flex(1)},
+		}
+	}
+	data := Rows{{"1", "long", "x"}, {"2", "y", "z"}}
+	table := New(columns(), data)
+	table.Resizable, table.Reorderable = true, true
+	draw(t, table, 12, 3)
+
+	press := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventPress, Col: 3}
+	motion := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventMotion}
+	release := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventRelease}
+
+	// Dragging the border after a column resizes it, down to its minimum width
+	if _, err := table.borders[0].HandleEvent(press, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	for _, col := range []int{5, 0} {
+		motion.Col = col
+		if _, err := table.HandleEvent(motion, vxfw.BubblePhase); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := table.HandleEvent(release, vxfw.BubblePhase); err != nil {
+		t.Fatal(err)
+	}
+	if got := table.Layout().Widths[0]; got != 2 {
+		t.Logf("wrong width after resizing, got=%d, want=2", got)
+		t.Fail()
+	}
+
+	// Double clicking the border fits the column to its content
+	draw(t, table, 12, 3)
+	for i := 0; i < 2; i++ {
+		if _, err := table.borders[1].HandleEvent(press, vxfw.TargetPhase); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := table.Layout().Widths[1]; got != 4 {
+		t.Logf("wrong width after fitting, got=%d, want=4", got)
+		t.Fail()
+	}
+
+	// Dragging a header over another moves it there, without sorting
+	if _, err := table.HandleEvent(release, vxfw.BubblePhase); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.headers[0].HandleEvent(press, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.headers[2].HandleEvent(motion, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.headers[2].HandleEvent(release, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.HandleEvent(release, vxfw.BubblePhase); err != nil {
+		t.Fatal(err)
+	}
+	if col, _ := table.SortedBy(); col != -1 {
+		t.Logf("wrong sort after moving a column, got=%d, want=-1", col)
+		t.Fail()
+	}
+
+	got := rows(draw(t, table, 12, 3))
+	want := []string{"b    c    a ", "long x    1 ", "y    z    2 "}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong row %d after moving, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+
+	// Clicking a header without dragging it sorts
+	if _, err := table.headers[0].HandleEvent(press, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.headers[0].HandleEvent(release, vxfw.TargetPhase); err != nil {
+		t.Fatal(err)
+	}
+	if col, _ := table.SortedBy(); col != 0 {
+		t.Logf("wrong sort after clicking, got=%d, want=0", col)
+		t.Fail()
+	}
+
+	// The layout can be restored to another table
+	restored := New(columns(), data)
+	restored.SetLayout(table.Layout())
+	if got := rows(draw(t, restored, 12, 3)); got[0] != want[0] {
+		t.Logf("wrong header of restored table, got=%q, want=%q", got[0], want[0])
+		t.Fail()
+	}
+}
+
+func TestTableSortMixed(t *testing.T) {
+	// Compared pairwise as numbers or strings, 9 < 10 < 1a < 9, so there's no consistent order
+	cells := []string{"1a", "10", "b", "9", "NaN", "A", "2"}
+	data := make(Rows, len(cells))
+	for i, cell := range cells {
+		data[i] = []string{cell}
+	}
+	table := New([]Column{{Title: "x", Sortable: true}}, data)
+	table.Sort(0, false)
+
+	want := []string{"2", "9", "10", "1a", "A", "b", "NaN"}
+	for view, cell := range want {
+		if got := cells[table.Row(view)]; got != cell {
+			t.Logf("wrong cell at %d, got=%q, want=%q", view, got, cell)
+			t.Fail()
+		}
+	}
+
+	for _, a := range cells {
+		for _, b := range cells {
+			if Compare(a, b) != -Compare(b, a) {
+				t.Logf("inconsistent comparison of %q and %q", a, b)
+				t.Fail()
+			}
+		}
+	}
+}
@@ -0,0 +1,404 @@
+// Package tabs provides a [Tabs] widget: a tab bar with switchable pages.
+package tabs
+
+import (
+	"math"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// Tab is a single tab in a [Tabs] widget. A Tab draws itself as its label in the tab bar, while
+// Page is drawn in the content area when the tab is active.
+type Tab struct {
+	Title string
+	Page  vxfw.Widget
+
+	// Closable tabs have a close button in the tab bar, and can be closed with a middle click.
+	Closable bool
+
+	owner    *Tabs
+	hover    bool
+	dragging bool
+	close    *closeButton
+}
+
+// NewTab returns a [Tab] with title that shows page when active.
+func NewTab(title string, page vxfw.Widget) *Tab {
+	return &Tab{Title: title, Page: page}
+}
+
+// StyleSet is the set of styles used to draw the tab bar.
+type StyleSet struct {
+	// Bar is the style of the tab bar behind the tabs
+	Bar    vaxis.Style
+	Tab    vaxis.Style
+	Active vaxis.Style
+	Hover  vaxis.Style
+}
+
+// Tabs is a stateful [vxfw.Widget] which shows a tab bar above the page of the active tab.
+// Inactive pages are not drawn, but since they are kept by Tabs their state is preserved.
+//
+// When Tabs (or a descendant) has focus, the following keys are captured:
+//
+//	Ctrl+Tab, Ctrl+PgDown          next tab
+//	Ctrl+Shift+Tab, Ctrl+PgUp      previous tab
+//	Alt+1 through Alt+9            select a tab by position
+//	Ctrl+Shift+PgDown, PgUp        move the active tab right or left
+//
+// Tabs can be selected by clicking them, reordered by dragging them and closed by clicking the
+// close button or middle clicking them.
+type Tabs struct {
+	Style StyleSet
+
+	// OnChange is called when the active tab changes.
+	OnChange func(active int) (vxfw.Command, error)
+	// OnClose is called after a tab is closed.
+	OnClose func(tab *Tab) (vxfw.Command, error)
+
+	tabs   []*Tab
+	active int
+}
+
+// New returns a [Tabs] widget with the given tabs. The first tab is active.
+func New(tabs ...*Tab) *Tabs {
+	t := &Tabs{
+		Style: StyleSet{
+			Bar:    vaxis.Style{Attribute: vaxis.AttrReverse},
+			Tab:    vaxis.Style{Attribute: vaxis.AttrReverse},
+			Active: vaxis.Style{Attribute: vaxis.AttrBold},
+			Hover: vaxis.Style{
+				Foreground: vaxis.IndexColor(3),
+				Attribute:  vaxis.AttrReverse,
+			},
+		},
+	}
+	for _, tab := range tabs {
+		t.Add(tab)
+	}
+	return t
+}
+
+// Add appends tab to the end of the tab bar.
+func (t *Tabs) Add(tab *Tab) {
+	tab.owner = t
+	tab.close = &closeButton{tab: tab}
+	t.tabs = append(t.tabs, tab)
+}
+
+// Len returns the number of tabs.
+func (t *Tabs) Len() int { return len(t.tabs) }
+
+// Tab returns the tab at index i.
+func (t *Tabs) Tab(i int) *Tab { return t.tabs[i] }
+
+// Active returns the index of the active tab.
+func (t *Tabs) Active() int { return t.active }
+
+// Index returns the index of tab, or -1 if it's not in t.
+func (t *Tabs) Index(tab *Tab) int {
+	for i, other := range t.tabs {
+		if other == tab {
+			return i
+		}
+	}
+	return -1
+}
+
+// Select makes the tab at index i active. Out of range indexes are ignored.
+func (t *Tabs) Select(i int) (vxfw.Command, error) {
+	if i < 0 || i >= len(t.tabs) || i == t.active {
+		return nil, nil
+	}
+	t.active = i
+	return t.changed()
+}
+
+// Next selects the next tab, wrapping around to the first.
+func (t *Tabs) Next() (vxfw.Command, error) {
+	if len(t.tabs) == 0 {
+		return nil, nil
+	}
+	return t.Select((t.active + 1) % len(t.tabs))
+}
+
+// Prev selects the previous tab, wrapping around to the last.
+func (t *Tabs) Prev() (vxfw.Command, error) {
+	if len(t.tabs) == 0 {
+		return nil, nil
+	}
+	return t.Select((t.active + len(t.tabs) - 1) % len(t.tabs))
+}
+
+// Move moves the tab at index from to index to. The active tab stays active.
+func (t *Tabs) Move(from, to int) {
+	if from < 0 || from >= len(t.tabs) || to < 0 || to >= len(t.tabs) || from == to {
+		return
+	}
+
+	active := t.tabs[t.active]
+	tab := t.tabs[from]
+	if from < to {
+		copy(t.tabs[from:to], t.tabs[from+1:to+1])
+	} else {
+		copy(t.tabs[to+1:from+1], t.tabs[to:from])
+	}
+	t.tabs[to] = tab
+	t.active = t.Index(active)
+}
+
+// Close removes the tab at index i. If it was active, the tab that takes its place becomes active.
+func (t *Tabs) Close(i int) (vxfw.Command, error) {
+	if i < 0 || i >= len(t.tabs) {
+		return nil, nil
+	}
+
+	tab := t.tabs[i]
+	t.tabs = append(t.tabs[:i], t.tabs[i+1:]...)
+	tab.owner = nil
+
+	wasActive := i == t.active
+	if i < t.active || t.active >= len(t.tabs) {
+		t.active -= 1
+	}
+	if t.active < 0 {
+		t.active = 0
+	}
+
+	cmds := []vxfw.Command{vxfw.RedrawCmd{}}
+	if t.OnClose != nil {
+		cmd, err := t.OnClose(tab)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+	if wasActive && len(t.tabs) > 0 {
+		cmd, err := t.changed()
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+func (t *Tabs) changed() (vxfw.Command, error) {
+	if t.OnChange == nil {
+		return vxfw.RedrawCmd{}, nil
+	}
+	cmd, err := t.OnChange(t.active)
+	if err != nil {
+		return nil, err
+	}
+	return []vxfw.Command{vxfw.RedrawCmd{}, cmd}, nil
+}
+
+func (t *Tabs) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+	key, ok := ev.(vaxis.Key)
+	if !ok || key.EventType == vaxis.EventRelease {
+		return nil, nil
+	}
+
+	var cmd vxfw.Command
+	var err error
+	switch {
+	case key.Matches(vaxis.KeyTab, vaxis.ModCtrl), key.Matches(vaxis.KeyPgDown, vaxis.ModCtrl):
+		cmd, err = t.Next()
+	case key.Matches(vaxis.KeyTab, vaxis.ModCtrl, vaxis.ModShift), key.Matches(vaxis.KeyPgUp, vaxis.ModCtrl):
+		cmd, err = t.Prev()
+	case key.Matches(vaxis.KeyPgDown, vaxis.ModCtrl, vaxis.ModShift):
+		t.Move(t.active, t.active+1)
+		cmd = vxfw.RedrawCmd{}
+	case key.Matches(vaxis.KeyPgUp, vaxis.ModCtrl, vaxis.ModShift):
+		t.Move(t.active, t.active-1)
+		cmd = vxfw.RedrawCmd{}
+	default:
+		// Alt+N is consumed even if tab N is already active, as long as there is one
+		n := -1
+		for i := 0; i < 9 && i < len(t.tabs); i++ {
+			if key.Matches('1'+rune(i), vaxis.ModAlt) {
+				n = i
+				break
+			}
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		cmd, err = t.Select(n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, nil
+}
+
+var _ vxfw.Widget = &Tabs{}
+
+func (t *Tabs) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if ctx.Max.HasUnboundedHeight() || ctx.Max.HasUnboundedWidth() {
+		panic("Tabs must have bounded constraints")
+	}
+
+	s := vxfw.NewSurface(ctx.Max.Width, ctx.Max.Height, t)
+
+	labels := make([]vxfw.Widget, len(t.tabs))
+	for i, tab := range t.tabs {
+		labels[i] = tab
+	}
+
+	bar := vxfw.NewSurface(ctx.Max.Width, 1, nil)
+	bar.FillStyle(t.Style.Bar)
+	row, err := vxlayout.Row(labels, vxlayout.Options{}).Draw(ctx.WithConstraints(
+		vxfw.Size{},
+		vxfw.Size{Width: ctx.Max.Width, Height: 1},
+	))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	// If the tabs overflow the bar, scroll them so the active tab is visible
+	if t.active < len(row.Children) {
+		label := row.Children[t.active]
+		end := label.Origin.Col + int(label.Surface.Size.Width)
+		if end > int(ctx.Max.Width) {
+			shift := end - int(ctx.Max.Width)
+			for i := range row.Children {
+				row.Children[i].Origin.Col -= shift
+			}
+		}
+	}
+	bar.AddChild(0, 0, row)
+	s.AddChild(0, 0, bar)
+
+	if len(t.tabs) == 0 || ctx.Max.Height < 2 {
+		return s, nil
+	}
+
+	page := t.tabs[t.active].Page
+	size := vxfw.Size{Width: ctx.Max.Width, Height: ctx.Max.Height - 1}
+	content, err := page.Draw(ctx.WithConstraints(size, size))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	s.AddChild(0, 1, content)
+
+	return s, nil
+}
+
+// Draw draws the label of the tab in the tab bar.
+func (tab *Tab) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	style := tab.owner.Style.Tab
+	switch {
+	case tab.owner.tabs[tab.owner.active] == tab:
+		style = tab.owner.Style.Active
+	case tab.hover:
+		style = tab.owner.Style.Hover
+	}
+
+	title := text.New(" " + tab.Title + " ")
+	title.Softwrap = false
+	title.Style = style
+
+	children := []vxfw.Widget{title}
+	if tab.Closable {
+		tab.close.style = style
+		children = append(children, tab.close)
+	}
+
+	ctx = ctx.WithConstraints(vxfw.Size{}, vxfw.Size{Width: math.MaxUint16, Height: 1})
+	s, err := vxlayout.Row(children, vxlayout.Options{}).Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	// The row takes all of the available width, shrink it to the label
+	var width uint16
+	for _, child := range s.Children {
+		width += child.Surface.Size.Width
+	}
+	s.Size.Width = width
+	s.Widget = tab
+	return s, nil
+}
+
+func (tab *Tab) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	if tab.owner == nil {
+		return nil, nil
+	}
+	owner := tab.owner
+
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		switch {
+		case ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseLeftButton:
+			for _, other := range owner.tabs {
+				other.dragging = false
+			}
+			tab.dragging = true
+			cmd, err := owner.Select(owner.Index(tab))
+			return []vxfw.Command{cmd, vxfw.ConsumeAndRedraw()}, err
+		case ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseMiddleButton && tab.Closable:
+			cmd, err := owner.Close(owner.Index(tab))
+			return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+		case ev.EventType == vaxis.EventMotion && ev.Button == vaxis.MouseLeftButton:
+			// Another tab is being dragged over this one, swap places with it
+			for i, other := range owner.tabs {
+				if other.dragging && other != tab {
+					owner.Move(i, owner.Index(tab))
+					return vxfw.ConsumeAndRedraw(), nil
+				}
+			}
+		case ev.EventType == vaxis.EventRelease || ev.EventType == vaxis.EventMotion:
+			// The drag is over when the button is released, or if the mouse moves without it,
+			// because it was released somewhere the tab didn't see
+			for _, other := range owner.tabs {
+				other.dragging = false
+			}
+		}
+	case vxfw.MouseEnter:
+		tab.hover = true
+		return []vxfw.Command{
+			vxfw.SetMouseShapeCmd(vaxis.MouseShapeClickable),
+			vxfw.RedrawCmd{},
+		}, nil
+	case vxfw.MouseLeave:
+		tab.hover = false
+		return []vxfw.Command{
+			vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault),
+			vxfw.RedrawCmd{},
+		}, nil
+	}
+	return nil, nil
+}
+
+// closeButton is drawn at the end of a closable tab's label.
+type closeButton struct {
+	tab   *Tab
+	style vaxis.Style
+}
+
+func (c *closeButton) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	s := vxfw.NewSurface(2, 1, c)
+	s.FillStyle(c.style)
+	s.WriteCell(0, 0, vaxis.Cell{
+		Character: vaxis.Character{Grapheme: "×", Width: 1},
+		Style:     c.style,
+	})
+	return s, nil
+}
+
+func (c *closeButton) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok || c.tab.owner == nil {
+		return nil, nil
+	}
+	if mouse.EventType == vaxis.EventPress && mouse.Button == vaxis.MouseLeftButton {
+		cmd, err := c.tab.owner.Close(c.tab.owner.Index(c.tab))
+		return []vxfw.Command{cmd, vxfw.ConsumeEventCmd{}}, err
+	}
+	return nil, nil
+}
@@ -0,0 +1,159 @@
+package tabs
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+)
+
+func titles(t *Tabs) []string {
+	out := make([]string, t.Len())
+	for i := range out {
+		out[i] = t.Tab(i).Title
+	}
+	return out
+}
+
+func TestTabsMoveAndClose(t *testing.T) {
+	tabs := New(
+		NewTab("a", text.New("page a")),
+		NewTab("b", text.New("page b")),
+		NewTab("c", text.New("page c")),
+	)
+	if _, err := tabs.Select(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving tabs around keeps the same tab active
+	tabs.Move(1, 2)
+	if got := titles(tabs); got[0] != "a" || got[1] != "c" || got[2] != "b" {
+		t.Logf("wrong tab order after move, got=%v, want=[a c b]", got)
+		t.Fail()
+	}
+	if tabs.Active() != 2 {
+		t.Logf("wrong active tab after move, got=%d, want=2", tabs.Active())
+		t.Fail()
+	}
+
+	// Closing a tab before the active tab keeps the same tab active
+	if _, err := tabs.Close(0); err != nil {
+		t.Fatal(err)
+	}
+	if tabs.Active() != 1 || tabs.Tab(1).Title != "b" {
+		t.Logf("wrong active tab after close, got=%d, want=1", tabs.Active())
+		t.Fail()
+	}
+
+	// Closing the last, active tab activates the tab before it
+	if _, err := tabs.Close(1); err != nil {
+		t.Fatal(err)
+	}
+	if tabs.Active() != 0 || tabs.Tab(0).Title != "c" {
+		t.Logf("wrong active tab after closing active tab, got=%d, want=0", tabs.Active())
+		t.Fail()
+	}
+}
+
+func TestTabsDraw(t *testing.T) {
+	tabs := New(NewTab("one", text.New("page one")), NewTab("two", text.New("page two")))
+	tabs.Tab(1).Closable = true
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: 5}, Characters: vaxis.Characters}
+	surface, err := tabs.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(surface.Children) != 2 {
+		t.Fatalf("wrong number of children, got=%d, want=2", len(surface.Children))
+	}
+
+	// The bar contains a row with a label for each tab
+	row := surface.Children[0].Surface.Children[0].Surface
+	widths := []uint16{5, 7}
+	for i, want := range widths {
+		if got := row.Children[i].Surface.Size.Width; got != want {
+			t.Logf("wrong width for tab %d, got=%d, want=%d", i, got, want)
+			t.Fail()
+		}
+	}
+
+	page := surface.Children[1]
+	if page.Origin.Row != 1 || page.Surface.Size.Height != 4 {
+		t.Logf("wrong page placement, got=%v %v", page.Origin, page.Surface.Size)
+		t.Fail()
+	}
+}
+
+func TestTabsAltNumber(t *testing.T) {
+	tabs := New(NewTab("a", text.New("page a")), NewTab("b", text.New("page b")))
+
+	consumed := func(cmd vxfw.Command) bool {
+		for _, c := range cmd.([]vxfw.Command) {
+			if _, ok := c.(vxfw.ConsumeEventCmd); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Alt+N for the active tab is consumed, so it doesn't reach other handlers
+	cmd, err := tabs.CaptureEvent(vaxis.Key{Keycode: '1', Modifiers: vaxis.ModAlt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd == nil || !consumed(cmd) {
+		t.Log("Alt+1 for the active tab wasn't consumed")
+		t.Fail()
+	}
+
+	cmd, err = tabs.CaptureEvent(vaxis.Key{Keycode: '2', Modifiers: vaxis.ModAlt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd == nil || !consumed(cmd) || tabs.Active() != 1 {
+		t.Logf("wrong result of Alt+2, active=%d, want=1", tabs.Active())
+		t.Fail()
+	}
+
+	// Alt+N without a tab N is left for other handlers
+	if cmd, _ := tabs.CaptureEvent(vaxis.Key{Keycode: '3', Modifiers: vaxis.ModAlt}); cmd != nil {
+		t.Logf("Alt+3 without a third tab was handled, got=%v", cmd)
+		t.Fail()
+	}
+}
+
+func TestTabsDrag(t *testing.T) {
+	tabs := New(
+		NewTab("a", text.New("page a")),
+		NewTab("b", text.New("page b")),
+		NewTab("c", text.New("page c")),
+	)
+	mouse := func(tab int, typ vaxis.EventType, button vaxis.MouseButton) {
+		ev := vaxis.Mouse{EventType: typ, Button: button}
+		if _, err := tabs.Tab(tab).HandleEvent(ev, vxfw.TargetPhase); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a is pressed, but the button is released outside of the bar, so no tab sees it
+	mouse(0, vaxis.EventPress, vaxis.MouseLeftButton)
+
+	// Dragging b over c moves b, not a
+	mouse(1, vaxis.EventPress, vaxis.MouseLeftButton)
+	mouse(2, vaxis.EventMotion, vaxis.MouseLeftButton)
+	if got := titles(tabs); got[0] != "a" || got[1] != "c" || got[2] != "b" {
+		t.Logf("wrong tab order after drag, got=%v, want=[a c b]", got)
+		t.Fail()
+	}
+
+	// Moving the mouse without the button held ends the drag
+	mouse(1, vaxis.EventMotion, vaxis.MouseNoButton)
+	mouse(0, vaxis.EventMotion, vaxis.MouseLeftButton)
+	if got := titles(tabs); got[0] != "a" || got[1] != "c" || got[2] != "b" {
+		t.Logf("tab moved after the drag ended, got=%v, want=[a c b]", got)
+		t.Fail()
+	}
+}
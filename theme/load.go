@@ -0,0 +1,325 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+var (
+	// ErrSyntax is returned for a theme file which can't be parsed.
+	ErrSyntax = errors.New("syntax error")
+	// ErrUnknownKey is returned for a key which has no meaning in a theme file.
+	ErrUnknownKey = errors.New("unknown key")
+	// ErrMissingKey is returned when a theme file doesn't define a required key.
+	ErrMissingKey = errors.New("missing key")
+	// ErrInvalidValue is returned for a value of the wrong type, or an invalid color or
+	// attribute.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// ParseError describes an error in a theme file, and where it happened.
+type ParseError struct {
+	// File is the name of the file, if the theme was loaded with [LoadFile].
+	File string
+	// Line is the line of the error, starting at 1. It's 0 for errors which aren't about a
+	// particular line, like a missing key.
+	Line int
+	// Key is the full name of the offending key, like "roles.primary.fg", if any.
+	Key string
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	b.WriteString("theme: ")
+	if e.File != "" {
+		b.WriteString(e.File)
+		b.WriteString(": ")
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", e.Line)
+	}
+	if e.Key != "" {
+		fmt.Fprintf(&b, "%s: ", e.Key)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// LoadFile loads a theme from a file, using [LoadBase16] for files ending in .yaml or .yml and
+// [LoadTOML] for files ending in .toml.
+func LoadFile(path string) (*Theme, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var t *Theme
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		t, err = LoadBase16(f)
+	case ".toml":
+		t, err = LoadTOML(f)
+	default:
+		return nil, fmt.Errorf("theme: %s: unknown theme format %q", path, ext)
+	}
+
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		perr.File = path
+	}
+	return t, err
+}
+
+// Base16 is a base16 color scheme. See https://github.com/tinted-theming/home for the scheme
+// format and the intended use of each color.
+type Base16 struct {
+	Name   string
+	Author string
+	// Colors are base00 through base0F.
+	Colors [16]vaxis.Color
+}
+
+// ParseBase16 parses a base16 scheme in YAML. Both the original format, with the colors at the top
+// level, and the newer format, with the colors under "palette", are supported.
+//
+// Only the subset of YAML used by scheme files is understood: comments, and string values which
+// are optionally quoted, nested at most one level.
+func ParseBase16(r io.Reader) (*Base16, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := &Base16{}
+	var seen [16]bool
+	section := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		lineno := i + 1
+		line = stripYAMLComment(line)
+		if strings.TrimSpace(line) == "" || strings.TrimSpace(line) == "---" {
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			return nil, &ParseError{Line: lineno, Err: fmt.Errorf("%w: expected key: value", ErrSyntax)}
+		}
+		key = strings.TrimSpace(key)
+		value, err = unquoteYAML(strings.TrimSpace(value))
+		if err != nil {
+			return nil, &ParseError{Line: lineno, Key: key, Err: err}
+		}
+
+		if !indented {
+			section = ""
+			if value == "" {
+				// The start of a nested mapping
+				section = key
+				continue
+			}
+		} else if section != "" {
+			key = section + "." + key
+		}
+
+		switch {
+		case key == "scheme" || key == "name":
+			scheme.Name = value
+		case key == "author":
+			scheme.Author = value
+		case isBase16Key(strings.TrimPrefix(key, "palette.")):
+			n, _ := strconv.ParseUint(strings.TrimPrefix(key, "palette.")[4:], 16, 8)
+			c, err := parseHex(strings.TrimPrefix(value, "#"))
+			if err != nil {
+				return nil, &ParseError{Line: lineno, Key: key, Err: err}
+			}
+			scheme.Colors[n] = c
+			seen[n] = true
+		}
+		// Other keys, like "system", "slug" or "variant", don't affect the colors
+	}
+
+	for n, ok := range seen {
+		if !ok {
+			return nil, &ParseError{Key: fmt.Sprintf("base%02X", n), Err: ErrMissingKey}
+		}
+	}
+	return scheme, nil
+}
+
+// LoadBase16 parses a base16 scheme with [ParseBase16] and returns its [Base16.Theme].
+func LoadBase16(r io.Reader) (*Theme, error) {
+	scheme, err := ParseBase16(r)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.Theme(), nil
+}
+
+// Theme returns a [Theme] which styles every role of [Default] with the colors of the scheme,
+// following the base16 styling guidelines.
+func (b *Base16) Theme() *Theme {
+	bg, fg := b.Colors[0x00], b.Colors[0x05]
+	on := func(c vaxis.Color) vaxis.Style {
+		return vaxis.Style{Foreground: c, Background: bg}
+	}
+
+	primary := on(b.Colors[0x0D])
+	primary.Attribute = vaxis.AttrBold
+	header := vaxis.Style{Foreground: fg, Background: b.Colors[0x01], Attribute: vaxis.AttrBold}
+
+	return New(map[Role]vaxis.Style{
+		Normal:    on(fg),
+		Primary:   primary,
+		Secondary: on(b.Colors[0x0C]),
+		Muted:     on(b.Colors[0x03]),
+		Border:    on(b.Colors[0x03]),
+		Header:    header,
+		Selection: {Foreground: fg, Background: b.Colors[0x02]},
+		Error:     on(b.Colors[0x08]),
+		Warning:   on(b.Colors[0x0A]),
+		Success:   on(b.Colors[0x0B]),
+	})
+}
+
+func isBase16Key(key string) bool {
+	if len(key) != 6 || !strings.HasPrefix(key, "base") {
+		return false
+	}
+	_, err := strconv.ParseUint(key[4:], 16, 8)
+	return err == nil
+}
+
+// stripYAMLComment removes a comment from line, unless the # is quoted.
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return strings.TrimRight(line, "\r")
+}
+
+func unquoteYAML(value string) (string, error) {
+	if len(value) < 2 {
+		return value, nil
+	}
+	switch value[0] {
+	case '"':
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: bad string %s", ErrSyntax, value)
+		}
+		return s, nil
+	case '\'':
+		if value[len(value)-1] != '\'' {
+			return "", fmt.Errorf("%w: bad string %s", ErrSyntax, value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
+
+// ansiNames are the names of the 16 ANSI colors, by index. "bright-" (or "bright") can be put in
+// front of the first 8 to get the last 8.
+var ansiNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ParseColor parses a color, written as hex ("#268bd2" or "#fff"), as an index into the 256 color
+// palette ("12"), as the name of an ANSI color ("red", "bright-blue"), or as "default".
+func ParseColor(s string) (vaxis.Color, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case name == "default" || name == "":
+		return vaxis.ColorDefault, nil
+	case strings.HasPrefix(name, "#"):
+		return parseHex(name[1:])
+	}
+
+	if n, err := strconv.ParseUint(name, 10, 8); err == nil {
+		return vaxis.IndexColor(uint8(n)), nil
+	}
+
+	bright := false
+	for _, prefix := range []string{"bright-", "bright_", "bright"} {
+		if strings.HasPrefix(name, prefix) {
+			name, bright = strings.TrimPrefix(name, prefix), true
+			break
+		}
+	}
+	if name == "grey" || name == "gray" {
+		// Gray is usually the bright variant of black
+		name, bright = "black", true
+	}
+	for i, n := range ansiNames {
+		if n == name {
+			if bright {
+				i += 8
+			}
+			return vaxis.IndexColor(uint8(i)), nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown color %q", ErrInvalidValue, s)
+}
+
+func parseHex(s string) (vaxis.Color, error) {
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return 0, fmt.Errorf("%w: bad hex color %q", ErrInvalidValue, s)
+	}
+	return vaxis.HexColor(uint32(v)), nil
+}
+
+var attributeNames = map[string]vaxis.AttributeMask{
+	"bold":          vaxis.AttrBold,
+	"dim":           vaxis.AttrDim,
+	"italic":        vaxis.AttrItalic,
+	"blink":         vaxis.AttrBlink,
+	"reverse":       vaxis.AttrReverse,
+	"invisible":     vaxis.AttrInvisible,
+	"hidden":        vaxis.AttrInvisible,
+	"strikethrough": vaxis.AttrStrikethrough,
+}
+
+var underlineNames = map[string]vaxis.UnderlineStyle{
+	"underline":        vaxis.UnderlineSingle,
+	"double-underline": vaxis.UnderlineDouble,
+	"curly-underline":  vaxis.UnderlineCurly,
+	"dotted-underline": vaxis.UnderlineDotted,
+	"dashed-underline": vaxis.UnderlineDashed,
+}
+
+// applyAttribute adds the attribute or underline style called name to style.
+func applyAttribute(style *vaxis.Style, name string) error {
+	name = strings.ToLower(name)
+	if attr, ok := attributeNames[name]; ok {
+		style.Attribute |= attr
+		return nil
+	}
+	if ul, ok := underlineNames[name]; ok {
+		style.UnderlineStyle = ul
+		return nil
+	}
+	return fmt.Errorf("%w: unknown attribute %q", ErrInvalidValue, name)
+}
@@ -0,0 +1,155 @@
+package theme
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+const base16Scheme = `
+scheme: "Test Scheme" # a comment
+author: "Someone"
+base00: "000000"
+base01: "111111"
+base02: "222222"
+base03: "333333"
+base04: "444444"
+base05: "555555"
+base06: "666666"
+base07: "777777"
+base08: "880000"
+base09: "999999"
+base0A: "aaaa00"
+base0B: "00bb00"
+base0C: "00cccc"
+base0D: "0000dd"
+base0E: "eeeeee"
+base0F: "ffffff"
+`
+
+func TestLoadBase16(t *testing.T) {
+	// The newer format nests the colors under "palette"
+	lines := strings.Split(strings.TrimSpace(base16Scheme), "\n")
+	nested := "system: \"base16\"\nname: \"Test Scheme\"\npalette:\n"
+	for _, line := range lines[2:] {
+		nested += "  " + strings.Replace(line, ": \"", ": \"#", 1) + "\n"
+	}
+
+	for _, src := range []string{base16Scheme, nested} {
+		scheme, err := ParseBase16(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scheme.Name != "Test Scheme" {
+			t.Logf("wrong name, got=%q", scheme.Name)
+			t.Fail()
+		}
+
+		th := scheme.Theme()
+		want := vaxis.Style{Foreground: vaxis.HexColor(0x880000), Background: vaxis.HexColor(0x000000)}
+		if got := th.Style(Error); got != want {
+			t.Logf("wrong error style, got=%v, want=%v", got, want)
+			t.Fail()
+		}
+	}
+
+	// Missing colors and bad colors are reported
+	_, err := LoadBase16(strings.NewReader(strings.Replace(base16Scheme, "base0F", "# base0F", 1)))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Key != "base0F" || !errors.Is(err, ErrMissingKey) {
+		t.Logf("wrong error for a missing color, got=%v", err)
+		t.Fail()
+	}
+	_, err = LoadBase16(strings.NewReader(strings.Replace(base16Scheme, "880000", "88000g", 1)))
+	if !errors.As(err, &perr) || perr.Line != 12 || perr.Key != "base08" || !errors.Is(err, ErrInvalidValue) {
+		t.Logf("wrong error for a bad color, got=%v", err)
+		t.Fail()
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	src := `
+name = "Example"
+
+[colors]
+accent = "#268bd2" # trailing comment
+
+[roles]
+normal = { fg = "white", bg = 235 }
+primary = { fg = "accent", attrs = ["bold"] }
+
+[roles.error]
+fg = "bright-red"
+attrs = [
+	"bold",
+	"curly-underline",
+]
+`
+	th, err := LoadTOML(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role Role
+		want vaxis.Style
+	}{
+		{Normal, vaxis.Style{Foreground: vaxis.IndexColor(7), Background: vaxis.IndexColor(235)}},
+		{Primary, vaxis.Style{Foreground: vaxis.HexColor(0x268bd2), Attribute: vaxis.AttrBold}},
+		{Error, vaxis.Style{
+			Foreground:     vaxis.IndexColor(9),
+			Attribute:      vaxis.AttrBold,
+			UnderlineStyle: vaxis.UnderlineCurly,
+		}},
+		// Roles which aren't styled keep their default
+		{Selection, Default.Style(Selection)},
+	}
+	for _, tt := range tests {
+		if got := th.Style(tt.role); got != tt.want {
+			t.Logf("wrong style for %s, got=%+v, want=%+v", tt.role, got, tt.want)
+			t.Fail()
+		}
+	}
+
+	errs := []struct {
+		src  string
+		line int
+		key  string
+		err  error
+	}{
+		{"[roles]\nnormal = { fg = \"chartreuse\" }", 2, "roles.normal.fg", ErrInvalidValue},
+		{"[roles.primary]\nfg = 4\nfgg = 5", 3, "roles.primary.fgg", ErrUnknownKey},
+		{"[roles.primary]\nattrs = [\"bold\", \"loud\"]", 2, "roles.primary.attrs", ErrInvalidValue},
+		{"[roles]\nprimary.bg = 300", 2, "roles.primary.bg", ErrInvalidValue},
+		{"colour = 1", 1, "colour", ErrUnknownKey},
+		{"[roles]\nnormal = { fg = 1 }\n\nnormal.fg = 2", 4, "roles.normal.fg", ErrSyntax},
+		{"[roles\n", 1, "", ErrSyntax},
+		{"name = \"unterminated", 1, "", ErrSyntax},
+	}
+	for _, tt := range errs {
+		_, err := LoadTOML(strings.NewReader(tt.src))
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Line != tt.line || perr.Key != tt.key || !errors.Is(err, tt.err) {
+			t.Logf("wrong error for %q, got=%v, want line %d, key %q, %v", tt.src, err, tt.line, tt.key, tt.err)
+			t.Fail()
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme.toml")
+	if err := os.WriteFile(path, []byte("[roles.primary]\nfg = \"nope\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadFile(path)
+	want := "theme: " + path + ": line 2: roles.primary.fg: invalid value: unknown color \"nope\""
+	if err == nil || err.Error() != want {
+		t.Logf("wrong error, got=%v, want=%s", err, want)
+		t.Fail()
+	}
+}
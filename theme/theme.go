@@ -0,0 +1,142 @@
+// Package theme provides a [Theme], which maps named roles to styles, and a way to provide a
+// theme to a subtree of widgets.
+//
+// Widgets look up the theme provided by their closest ancestor with [From] while drawing, and
+// style themselves by role rather than with literal styles:
+//
+//	func (w *Widget) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+//		style := theme.From(ctx).Style(theme.Primary)
+//		...
+//	}
+//
+//	content := theme.Provide(myTheme, page)
+//	sidebar := theme.Override(map[theme.Role]vaxis.Style{theme.Normal: sidebarStyle}, list)
+package theme
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp"
+)
+
+// Role names the purpose of a style. Applications can define their own roles in addition to the
+// ones below.
+type Role string
+
+const (
+	// Normal is the style of regular content, and the fallback for roles a theme doesn't define.
+	Normal    Role = "normal"
+	Primary   Role = "primary"
+	Secondary Role = "secondary"
+	Muted     Role = "muted"
+	Border    Role = "border"
+	Header    Role = "header"
+	Selection Role = "selection"
+	Error     Role = "error"
+	Warning   Role = "warning"
+	Success   Role = "success"
+)
+
+// Theme maps roles to styles. A theme can extend another theme, in which case roles it doesn't
+// define are looked up in the theme it extends.
+type Theme struct {
+	parent *Theme
+	styles map[Role]vaxis.Style
+}
+
+// New returns a [Theme] with styles.
+func New(styles map[Role]vaxis.Style) *Theme {
+	t := &Theme{styles: make(map[Role]vaxis.Style, len(styles))}
+	for role, style := range styles {
+		t.styles[role] = style
+	}
+	return t
+}
+
+// Default is the theme used when no theme has been provided. It only uses attributes and the 16
+// ANSI colors, so it follows the color scheme of the terminal.
+var Default = New(map[Role]vaxis.Style{
+	Normal:    {},
+	Primary:   {Foreground: vaxis.IndexColor(4), Attribute: vaxis.AttrBold},
+	Secondary: {Foreground: vaxis.IndexColor(6)},
+	Muted:     {Attribute: vaxis.AttrDim},
+	Border:    {Foreground: vaxis.IndexColor(8)},
+	Header:    {Attribute: vaxis.AttrBold | vaxis.AttrReverse},
+	Selection: {Attribute: vaxis.AttrReverse},
+	Error:     {Foreground: vaxis.IndexColor(1)},
+	Warning:   {Foreground: vaxis.IndexColor(3)},
+	Success:   {Foreground: vaxis.IndexColor(2)},
+})
+
+// Extend returns a [Theme] with styles, which falls back to t for any other role.
+func (t *Theme) Extend(styles map[Role]vaxis.Style) *Theme {
+	out := New(styles)
+	out.parent = t
+	return out
+}
+
+// Style returns the style of role. If neither t nor the themes it extends define role, the style
+// of [Normal] is returned instead.
+func (t *Theme) Style(role Role) vaxis.Style {
+	if style, ok := t.lookup(role); ok {
+		return style
+	}
+	style, _ := t.lookup(Normal)
+	return style
+}
+
+// Has reports whether t, or a theme it extends, defines role.
+func (t *Theme) Has(role Role) bool {
+	_, ok := t.lookup(role)
+	return ok
+}
+
+func (t *Theme) lookup(role Role) (vaxis.Style, bool) {
+	for th := t; th != nil; th = th.parent {
+		if style, ok := th.styles[role]; ok {
+			return style, true
+		}
+	}
+	return vaxis.Style{}, false
+}
+
+// From returns the theme provided to the widget being drawn with ctx by its closest ancestor, or
+// [Default] if no theme was provided. See [vxexp.Lookup].
+func From(ctx vxfw.DrawContext) *Theme {
+	if t, ok := vxexp.Lookup[*Theme](ctx); ok && t != nil {
+		return t
+	}
+	return Default
+}
+
+// Provide returns a [vxfw.Widget] which draws child, and provides t to child and its
+// descendants. To theme a whole application, provide t to the content of its root widget from the
+// root's Draw, rather than wrapping the root: [vxfw.App] focuses the root it's given, so a root
+// wrapped by Provide wouldn't capture keys.
+func Provide(t *Theme, child vxfw.Widget) vxfw.Widget {
+	return vxexp.Provide(t, child)
+}
+
+// Override returns a [vxfw.Widget] which draws child, and provides it with the theme of its
+// ancestors extended with styles.
+func Override(styles map[Role]vaxis.Style, child vxfw.Widget) vxfw.Widget {
+	return vxexp.ProvideFunc(func(ctx vxfw.DrawContext) *Theme {
+		return From(ctx).Extend(styles)
+	}, child)
+}
+
+// Text returns a [vxfw.Widget] which draws s with [text.Text], styled with role.
+func Text(s string, role Role) vxfw.Widget {
+	return &styledText{text: text.New(s), role: role}
+}
+
+type styledText struct {
+	text *text.Text
+	role Role
+}
+
+func (t *styledText) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	t.text.Style = From(ctx).Style(t.role)
+	return t.text.Draw(ctx)
+}
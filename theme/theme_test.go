@@ -0,0 +1,71 @@
+package theme
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp"
+)
+
+func TestProvide(t *testing.T) {
+	base := New(map[Role]vaxis.Style{
+		Normal:  {Foreground: vaxis.IndexColor(7)},
+		Primary: {Foreground: vaxis.IndexColor(4)},
+	})
+
+	var got []*Theme
+	probe := vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		got = append(got, From(ctx))
+		return vxfw.NewSurface(1, 1, nil), nil
+	})
+
+	accent := vaxis.Style{Foreground: vaxis.IndexColor(1)}
+	tree := Provide(base, vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		if _, err := probe.Draw(ctx); err != nil {
+			return vxfw.Surface{}, err
+		}
+		// Derived contexts carry the theme too
+		return Override(map[Role]vaxis.Style{Primary: accent}, probe).Draw(ctx.WithMin(vxfw.Size{}))
+	}))
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 10, Height: 10}, Characters: vaxis.Characters}
+	if _, err := probe.Draw(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.Draw(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("wrong number of lookups, got=%d, want=3", len(got))
+	}
+	if got[0] != Default {
+		t.Logf("expected the default theme without a provider")
+		t.Fail()
+	}
+	if got[1] != base {
+		t.Logf("expected the provided theme")
+		t.Fail()
+	}
+	if style := got[2].Style(Primary); style != accent {
+		t.Logf("wrong overridden style, got=%v, want=%v", style, accent)
+		t.Fail()
+	}
+	// Roles which aren't overridden are inherited, and unknown roles fall back to Normal
+	if style := got[2].Style(Role("custom")); style != base.Style(Normal) {
+		t.Logf("wrong fallback style, got=%v, want=%v", style, base.Style(Normal))
+		t.Fail()
+	}
+
+	// Characters still works for ordinary strings below a provider
+	chars := vxfw.DrawContext{Characters: vaxis.Characters}
+	Provide(base, vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		chars = ctx
+		return vxfw.Surface{}, nil
+	})).Draw(chars)
+	if n := len(chars.Characters("abc")); n != 3 {
+		t.Logf("wrong number of characters, got=%d, want=3", n)
+		t.Fail()
+	}
+}
@@ -0,0 +1,419 @@
+package theme
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~rockorager/vaxis"
+)
+
+// LoadTOML loads a theme written in TOML. Roles are styled in the "roles" table, each with an
+// optional foreground color, background color and list of attributes. Colors can be given names
+// in the "colors" table and referred to by name:
+//
+//	name = "Example"
+//
+//	[colors]
+//	accent = "#268bd2"
+//
+//	[roles]
+//	normal = { fg = "white", bg = 235 }
+//	primary = { fg = "accent", attrs = ["bold"] }
+//
+//	[roles.error]
+//	fg = "bright-red"
+//	attrs = ["bold", "curly-underline"]
+//
+// Colors are parsed with [ParseColor]. The attributes are bold, dim, italic, blink, reverse,
+// invisible (or hidden), strikethrough, underline, double-underline, curly-underline,
+// dotted-underline and dashed-underline.
+//
+// The returned theme extends [Default], so roles which aren't styled keep their default style.
+// Only the subset of TOML needed by theme files is understood: tables, dotted keys, inline tables,
+// and string, integer, boolean and array values.
+func LoadTOML(r io.Reader) (*Theme, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseTOML(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Named colors are resolved first, so roles can refer to colors defined after them
+	colors := make(map[string]vaxis.Color)
+	for _, e := range entries {
+		if len(e.key) != 2 || e.key[0] != "colors" {
+			continue
+		}
+		c, err := e.color(nil)
+		if err != nil {
+			return nil, err
+		}
+		colors[e.key[1]] = c
+	}
+
+	styles := make(map[Role]vaxis.Style)
+	for _, e := range entries {
+		switch {
+		case len(e.key) == 1 && (e.key[0] == "name" || e.key[0] == "author"):
+			if _, ok := e.value.(string); !ok {
+				return nil, e.errorf("%w: expected a string", ErrInvalidValue)
+			}
+		case len(e.key) == 2 && e.key[0] == "colors":
+			// Resolved above
+		case len(e.key) == 3 && e.key[0] == "roles":
+			role := Role(e.key[1])
+			style := styles[role]
+			switch e.key[2] {
+			case "fg":
+				if style.Foreground, err = e.color(colors); err != nil {
+					return nil, err
+				}
+			case "bg":
+				if style.Background, err = e.color(colors); err != nil {
+					return nil, err
+				}
+			case "attrs":
+				list, ok := e.value.([]any)
+				if !ok {
+					return nil, e.errorf("%w: expected a list of attributes", ErrInvalidValue)
+				}
+				for _, item := range list {
+					name, ok := item.(string)
+					if !ok {
+						return nil, e.errorf("%w: expected a list of attributes", ErrInvalidValue)
+					}
+					if err := applyAttribute(&style, name); err != nil {
+						return nil, e.errorf("%w", err)
+					}
+				}
+			default:
+				return nil, e.errorf("%w", ErrUnknownKey)
+			}
+			styles[role] = style
+		default:
+			return nil, e.errorf("%w", ErrUnknownKey)
+		}
+	}
+	return Default.Extend(styles), nil
+}
+
+// tomlEntry is a key and its value. Values are strings, int64s, bools or []any.
+type tomlEntry struct {
+	key   []string
+	value any
+	line  int
+}
+
+func (e tomlEntry) errorf(format string, args ...any) *ParseError {
+	return &ParseError{Line: e.line, Key: strings.Join(e.key, "."), Err: fmt.Errorf(format, args...)}
+}
+
+// color returns the value of e as a color, which is either the name of a color in named, or a
+// color accepted by [ParseColor]. Integers are indexes into the 256 color palette.
+func (e tomlEntry) color(named map[string]vaxis.Color) (vaxis.Color, error) {
+	switch v := e.value.(type) {
+	case int64:
+		if v < 0 || v > 255 {
+			return 0, e.errorf("%w: color index %d is out of range", ErrInvalidValue, v)
+		}
+		return vaxis.IndexColor(uint8(v)), nil
+	case string:
+		if c, ok := named[v]; ok {
+			return c, nil
+		}
+		c, err := ParseColor(v)
+		if err != nil {
+			return 0, e.errorf("%w", err)
+		}
+		return c, nil
+	}
+	return 0, e.errorf("%w: expected a color", ErrInvalidValue)
+}
+
+// tomlParser parses the subset of TOML described in [LoadTOML] into a flat list of entries.
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+
+	table   []string
+	entries []tomlEntry
+	seen    map[string]bool
+}
+
+func parseTOML(src string) ([]tomlEntry, error) {
+	p := &tomlParser{src: src, line: 1, seen: make(map[string]bool)}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return p.entries, nil
+		}
+
+		if p.peek() == '[' {
+			if err := p.parseTable(); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := p.parseKeyValue(p.table); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool  { return p.pos >= len(p.src) }
+func (p *tomlParser) peek() byte { return p.src[p.pos] }
+
+func (p *tomlParser) syntaxError(format string, args ...any) *ParseError {
+	return &ParseError{Line: p.line, Err: fmt.Errorf("%w: "+format, append([]any{ErrSyntax}, args...)...)}
+}
+
+// skipSpace skips spaces and comments, and newlines if newlines is true.
+func (p *tomlParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.syntaxError("unexpected %q after value", p.peek())
+	}
+	return nil
+}
+
+func (p *tomlParser) parseTable() error {
+	p.pos++ // [
+	if !p.eof() && p.peek() == '[' {
+		return p.syntaxError("arrays of tables are not supported")
+	}
+	p.skipSpace(false)
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	if p.eof() || p.peek() != ']' {
+		return p.syntaxError("expected ] after table name")
+	}
+	p.pos++
+	p.table = key
+	return nil
+}
+
+// parseKey parses a dotted key.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var key []string
+	for {
+		p.skipSpace(false)
+		if p.eof() {
+			return nil, p.syntaxError("expected a key")
+		}
+
+		var part string
+		if c := p.peek(); c == '"' || c == '\'' {
+			s, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		} else {
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.syntaxError("expected a key")
+			}
+			part = p.src[start:p.pos]
+		}
+		key = append(key, part)
+
+		p.skipSpace(false)
+		if p.eof() || p.peek() != '.' {
+			return key, nil
+		}
+		p.pos++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// parseKeyValue parses key = value in table. Inline tables are flattened into an entry for each
+// of their keys.
+func (p *tomlParser) parseKeyValue(table []string) error {
+	line := p.line
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	full := append(append([]string{}, table...), key...)
+
+	p.skipSpace(false)
+	if p.eof() || p.peek() != '=' {
+		return p.syntaxError("expected = after %s", strings.Join(key, "."))
+	}
+	p.pos++
+	p.skipSpace(false)
+
+	if !p.eof() && p.peek() == '{' {
+		return p.parseInlineTable(full)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	name := strings.Join(full, ".")
+	if p.seen[name] {
+		return &ParseError{Line: line, Key: name, Err: fmt.Errorf("%w: duplicate key", ErrSyntax)}
+	}
+	p.seen[name] = true
+	p.entries = append(p.entries, tomlEntry{key: full, value: value, line: line})
+	return nil
+}
+
+func (p *tomlParser) parseInlineTable(table []string) error {
+	p.pos++ // {
+	p.skipSpace(false)
+	if !p.eof() && p.peek() == '}' {
+		p.pos++
+		return nil
+	}
+	for {
+		if err := p.parseKeyValue(table); err != nil {
+			return err
+		}
+		p.skipSpace(false)
+		if p.eof() {
+			return p.syntaxError("unterminated inline table")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+			p.skipSpace(false)
+		case '}':
+			p.pos++
+			return nil
+		default:
+			return p.syntaxError("expected , or } in inline table")
+		}
+	}
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.eof() {
+		return nil, p.syntaxError("expected a value")
+	}
+
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case strings.HasPrefix(p.src[p.pos:], "true"):
+		p.pos += len("true")
+		return true, nil
+	case strings.HasPrefix(p.src[p.pos:], "false"):
+		p.pos += len("false")
+		return false, nil
+	case c == '+' || c == '-' || c >= '0' && c <= '9':
+		start := p.pos
+		p.pos++
+		for !p.eof() && (p.peek() >= '0' && p.peek() <= '9' || p.peek() == '_') {
+			p.pos++
+		}
+		n, err := strconv.ParseInt(strings.ReplaceAll(p.src[start:p.pos], "_", ""), 10, 64)
+		if err != nil {
+			return nil, p.syntaxError("bad integer %q", p.src[start:p.pos])
+		}
+		return n, nil
+	}
+	return nil, p.syntaxError("unexpected %q", p.peek())
+}
+
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.peek()
+	start := p.pos
+	p.pos++
+	for !p.eof() && p.peek() != quote && p.peek() != '\n' {
+		if quote == '"' && p.peek() == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.eof() || p.peek() != quote {
+		return "", p.syntaxError("unterminated string")
+	}
+	p.pos++
+
+	raw := p.src[start:p.pos]
+	if quote == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+	s, err := strconv.Unquote(raw)
+	if err != nil {
+		return "", p.syntaxError("bad string %s", raw)
+	}
+	return s, nil
+}
+
+func (p *tomlParser) parseArray() ([]any, error) {
+	p.pos++ // [
+	out := []any{}
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.syntaxError("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return out, nil
+		}
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+
+		p.skipSpace(true)
+		if p.eof() {
+			return nil, p.syntaxError("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.syntaxError("expected , or ] in array")
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Package toast provides a [Toaster] widget which shows transient notifications on top of the
+// rest of the UI.
+package toast
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+// Severity determines the style and icon of a toast.
+type Severity int
+
+const (
+	Info Severity = iota
+	Success
+	Warning
+	Error
+)
+
+// Corner is the corner of the screen toasts are stacked in.
+type Corner int
+
+const (
+	TopRight Corner = iota
+	TopLeft
+	BottomRight
+	BottomLeft
+)
+
+// StyleSet is the style of toasts for each [Severity].
+type StyleSet struct {
+	Info    vaxis.Style
+	Success vaxis.Style
+	Warning vaxis.Style
+	Error   vaxis.Style
+}
+
+func (ss StyleSet) style(sev Severity) vaxis.Style {
+	switch sev {
+	case Success:
+		return ss.Success
+	case Warning:
+		return ss.Warning
+	case Error:
+		return ss.Error
+	default:
+		return ss.Info
+	}
+}
+
+var icons = map[Severity]string{
+	Info:    "ℹ ",
+	Success: "✔ ",
+	Warning: "⚠ ",
+	Error:   "✖ ",
+}
+
+// Toaster is a [vxfw.Widget] which draws Child, and stacks toasts on top of it in a corner.
+// Toasts are dismissed when they're clicked, or when their timeout expires.
+//
+// vxfw has no timer commands, so expired toasts are removed the next time the Toaster is drawn.
+// Set PostEvent (typically to [vxfw.App.PostEvent]) so the Toaster can request a redraw when a
+// toast expires.
+// Toasts can be shown from any goroutine.
+type Toaster struct {
+	Child vxfw.Widget
+
+	Corner Corner
+	Style  StyleSet
+	// Timeout is how long toasts are shown for. A timeout of 0 shows toasts until they're
+	// dismissed.
+	Timeout time.Duration
+	// MaxWidth is the maximum width of a toast.
+	MaxWidth uint16
+	// Gap is the number of rows between toasts.
+	Gap uint16
+
+	// PostEvent is used to schedule a redraw when a toast expires.
+	PostEvent func(vaxis.Event)
+
+	mu     sync.Mutex
+	toasts []*toast
+	now    func() time.Time
+}
+
+// New returns a [Toaster] which draws child.
+func New(child vxfw.Widget) *Toaster {
+	return &Toaster{
+		Child: child,
+		Style: StyleSet{
+			Info:    vaxis.Style{Attribute: vaxis.AttrReverse},
+			Success: vaxis.Style{Foreground: vaxis.IndexColor(0), Background: vaxis.IndexColor(2)},
+			Warning: vaxis.Style{Foreground: vaxis.IndexColor(0), Background: vaxis.IndexColor(3)},
+			Error:   vaxis.Style{Foreground: vaxis.IndexColor(15), Background: vaxis.IndexColor(1)},
+		},
+		Timeout:  5 * time.Second,
+		MaxWidth: 40,
+		Gap:      1,
+	}
+}
+
+// Show adds a toast with message. The returned command redraws the UI, for use from an event
+// handler. When calling Show from another goroutine, post a [vaxis.Redraw] event instead.
+func (t *Toaster) Show(message string, severity Severity) vxfw.Command {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tt := &toast{owner: t, message: message, severity: severity}
+	if t.Timeout > 0 {
+		tt.expires = t.clock().Add(t.Timeout)
+		if t.PostEvent != nil {
+			post := t.PostEvent
+			time.AfterFunc(t.Timeout, func() { post(vaxis.Redraw{}) })
+		}
+	}
+	t.toasts = append(t.toasts, tt)
+	return vxfw.RedrawCmd{}
+}
+
+// Len returns the number of toasts being shown.
+func (t *Toaster) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.toasts)
+}
+
+// Clear dismisses all toasts.
+func (t *Toaster) Clear() vxfw.Command {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.toasts = nil
+	return vxfw.RedrawCmd{}
+}
+
+// clock returns the current time.
+func (t *Toaster) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+	return time.Now()
+}
+
+func (t *Toaster) dismiss(tt *toast) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, other := range t.toasts {
+		if other == tt {
+			t.toasts = append(t.toasts[:i], t.toasts[i+1:]...)
+			return
+		}
+	}
+}
+
+var _ vxfw.Widget = &Toaster{}
+
+func (t *Toaster) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	child, err := t.Child.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	s := vxfw.Surface{Size: child.Size, Widget: t}
+	s.AddChild(0, 0, child)
+
+	// Remove expired toasts
+	t.mu.Lock()
+	now := t.clock()
+	live := t.toasts[:0]
+	for _, tt := range t.toasts {
+		if tt.expires.IsZero() || now.Before(tt.expires) {
+			live = append(live, tt)
+		}
+	}
+	t.toasts = live
+	widgets := make([]vxfw.Widget, len(live))
+	for i, tt := range live {
+		widgets[i] = tt
+	}
+	t.mu.Unlock()
+
+	max := vxfw.Size{Width: t.MaxWidth, Height: child.Size.Height}
+	if max.Width > child.Size.Width {
+		max.Width = child.Size.Width
+	}
+
+	// Only the newest toasts which fit in the child are shown
+	measure := ctx.WithConstraints(vxfw.Size{}, vxfw.Size{Width: max.Width, Height: math.MaxUint16})
+	height, keep := 0, len(widgets)
+	for i := len(widgets) - 1; i >= 0; i-- {
+		toast, err := widgets[i].Draw(measure)
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+		h := int(toast.Size.Height)
+		if i < len(widgets)-1 {
+			h += int(t.Gap)
+		}
+		if height+h > int(max.Height) {
+			break
+		}
+		height, keep = height+h, i
+	}
+	widgets = widgets[keep:]
+
+	if len(widgets) == 0 {
+		return s, nil
+	}
+
+	opts := vxlayout.Options{Gap: t.Gap, CrossAxis: vxlayout.CrossAxisEnd}
+	if t.Corner == TopLeft || t.Corner == BottomLeft {
+		opts.CrossAxis = vxlayout.CrossAxisStart
+	}
+	if t.Corner == BottomLeft || t.Corner == BottomRight {
+		// The newest toast is closest to the corner
+		opts.MainAxis = vxlayout.MainAxisEnd
+		for i, j := 0, len(widgets)-1; i < j; i, j = i+1, j-1 {
+			widgets[i], widgets[j] = widgets[j], widgets[i]
+		}
+	}
+
+	stack, err := vxlayout.Column(widgets, opts).Draw(ctx.WithConstraints(vxfw.Size{}, max))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	// The column takes the full height, shrink it to the toasts so that it doesn't cover the
+	// child where there are no toasts.
+	first := stack.Children[0].Origin.Row
+	for i := range stack.Children {
+		stack.Children[i].Origin.Row -= first
+	}
+	last := stack.Children[len(stack.Children)-1]
+	stack.Size.Height = uint16(last.Origin.Row + int(last.Surface.Size.Height))
+
+	col, row := 0, 0
+	if opts.CrossAxis == vxlayout.CrossAxisEnd {
+		col = int(child.Size.Width) - int(stack.Size.Width)
+	}
+	if opts.MainAxis == vxlayout.MainAxisEnd {
+		row = first
+	}
+
+	sub := vxfw.NewSubSurface(col, row, stack)
+	sub.ZIndex = 1
+	s.Children = append(s.Children, sub)
+	return s, nil
+}
+
+// toast is a single notification in a [Toaster].
+type toast struct {
+	owner    *Toaster
+	message  string
+	severity Severity
+	expires  time.Time
+}
+
+func (tt *toast) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	style := tt.owner.Style.style(tt.severity)
+
+	msg := text.New(icons[tt.severity] + tt.message)
+	msg.Style = style
+
+	// Leave room for one column of padding on each side
+	max := ctx.Max
+	if max.Width > 2 {
+		max.Width -= 2
+	}
+	content, err := msg.Draw(ctx.WithConstraints(vxfw.Size{}, max))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	s := vxfw.NewSurface(content.Size.Width+2, content.Size.Height, tt)
+	s.FillStyle(style)
+	s.AddChild(1, 0, content)
+	return s, nil
+}
+
+func (tt *toast) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		if ev.EventType == vaxis.EventPress && ev.Button == vaxis.MouseLeftButton {
+			tt.owner.dismiss(tt)
+			return []vxfw.Command{
+				vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault),
+				vxfw.ConsumeAndRedraw(),
+			}, nil
+		}
+	case vxfw.MouseEnter:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeClickable), nil
+	case vxfw.MouseLeave:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault), nil
+	}
+	return nil, nil
+}
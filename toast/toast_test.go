@@ -0,0 +1,90 @@
+package toast
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/vxlayout"
+)
+
+func TestToaster(t *testing.T) {
+	now := time.Now()
+	toaster := New(vxlayout.Fill(vaxis.Cell{}))
+	toaster.now = func() time.Time { return now }
+
+	toaster.Show("saved", Success)
+	now = now.Add(time.Second)
+	toaster.Show("failed", Error)
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 30, Height: 10}, Characters: vaxis.Characters}
+	s, err := toaster.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Children) != 2 {
+		t.Fatalf("wrong number of children, got=%d, want=2", len(s.Children))
+	}
+
+	// Toasts are stacked in the top right corner with a gap between them
+	stack := s.Children[1]
+	if stack.ZIndex != 1 || stack.Surface.Size.Height != 3 {
+		t.Logf("wrong stack, got size=%v z=%d", stack.Surface.Size, stack.ZIndex)
+		t.Fail()
+	}
+	// "✖ failed" plus padding is the widest toast
+	if stack.Origin.Col != 30-10 || stack.Origin.Row != 0 {
+		t.Logf("wrong stack origin, got=%v", stack.Origin)
+		t.Fail()
+	}
+
+	// The first toast expires
+	now = now.Add(toaster.Timeout - time.Millisecond)
+	if _, err := toaster.Draw(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if toaster.Len() != 1 {
+		t.Logf("wrong number of toasts after expiry, got=%d, want=1", toaster.Len())
+		t.Fail()
+	}
+}
+
+func TestToasterOverflow(t *testing.T) {
+	toaster := New(vxlayout.Fill(vaxis.Cell{}))
+	toaster.Corner = BottomRight
+	for _, message := range []string{"a", "b", "c", "d"} {
+		toaster.Show(message, Info)
+	}
+
+	// Only the two newest toasts, and the gap between them, fit
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 30, Height: 4}, Characters: vaxis.Characters}
+	s, err := toaster.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack := s.Children[1]
+	if stack.Origin.Row != 1 || stack.Surface.Size.Height != 3 {
+		t.Logf("wrong stack, got origin=%v size=%v, want row 1 and height 3", stack.Origin, stack.Surface.Size)
+		t.Fail()
+	}
+	if toaster.Len() != 4 {
+		t.Logf("toasts which don't fit should be kept, got=%d, want=4", toaster.Len())
+		t.Fail()
+	}
+}
+
+func TestToasterZeroValue(t *testing.T) {
+	toaster := &Toaster{Child: vxlayout.Fill(vaxis.Cell{}), Timeout: time.Second}
+	toaster.Show("saved", Success)
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 30, Height: 10}, Characters: vaxis.Characters}
+	if _, err := toaster.Draw(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if toaster.Len() != 1 {
+		t.Logf("wrong number of toasts, got=%d, want=1", toaster.Len())
+		t.Fail()
+	}
+}
@@ -0,0 +1,642 @@
+// Package tree provides a [Tree] widget which shows nested [Node]s, loading their children as they
+// are expanded.
+package tree
+
+import (
+	"sync"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// Node is an item in a [Tree].
+type Node struct {
+	Label string
+	// Value is for the application to associate data with the node, like a path.
+	Value any
+	// Leaf nodes have no children, and can't be expanded.
+	Leaf bool
+
+	parent   *Node
+	children []*Node
+	loaded   bool
+	loading  bool
+	err      error
+	expanded bool
+}
+
+// NewNode returns a [Node] with label and value, whose children are loaded by the [Provider] of
+// its tree when it's first expanded.
+func NewNode(label string, value any) *Node {
+	return &Node{Label: label, Value: value}
+}
+
+// NewLeaf returns a [Node] with label and value which has no children.
+func NewLeaf(label string, value any) *Node {
+	return &Node{Label: label, Value: value, Leaf: true}
+}
+
+// SetChildren sets the children of n, so they don't need to be loaded. It's not safe to call while
+// n is in a tree that's being drawn; use a [Provider] to load children instead.
+func (n *Node) SetChildren(children ...*Node) *Node {
+	for _, c := range children {
+		c.parent = n
+	}
+	n.children, n.loaded, n.err = children, true, nil
+	return n
+}
+
+// Parent returns the parent of n, or nil for the root.
+func (n *Node) Parent() *Node { return n.parent }
+
+// Children returns the children of n which have been loaded.
+func (n *Node) Children() []*Node { return n.children }
+
+// Expanded reports whether the children of n are shown.
+func (n *Node) Expanded() bool { return n.expanded }
+
+// Err returns the error from loading the children of n, if any.
+func (n *Node) Err() error { return n.err }
+
+// Provider loads the children of nodes.
+type Provider interface {
+	// Children loads the children of node, and calls done with them or with an error. done can
+	// be called before Children returns, or later from another goroutine, so children can be
+	// loaded in the background:
+	//
+	//	func (p *provider) Children(node *tree.Node, done func([]*tree.Node, error)) {
+	//		go func() {
+	//			done(p.list(node.Value.(string)))
+	//		}()
+	//	}
+	Children(node *Node, done func(children []*Node, err error))
+}
+
+// ProviderFunc is a [Provider] which loads children by calling the function, before Children
+// returns.
+type ProviderFunc func(node *Node) ([]*Node, error)
+
+func (f ProviderFunc) Children(node *Node, done func([]*Node, error)) { done(f(node)) }
+
+// spinner is shown in place of the icon of a node whose children are loading.
+var spinner = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is how long each frame of the spinner is shown for.
+const spinnerInterval = 80 * time.Millisecond
+
+// StyleSet is the set of styles used to draw a tree.
+type StyleSet struct {
+	Node   vaxis.Style
+	Cursor vaxis.Style
+	Guide  vaxis.Style
+	Error  vaxis.Style
+}
+
+// Tree is a stateful [vxfw.Widget] which shows a tree of nodes, with guide lines from each node to
+// its children. Only the rows in view are drawn.
+//
+// The children of a node are loaded by Provider when the node is first expanded. While they load,
+// a spinner is shown in front of the node. If loading fails, the error is shown after the node's
+// label, and expanding it again retries.
+//
+// When Tree has focus, the following keys are handled:
+//
+//	Up, k / Down, j      move the cursor
+//	PgUp / PgDown        move the cursor by a page
+//	Home, g / End, G     move the cursor to the first or last node
+//	Right, l             expand the node, or move to its first child if it's expanded
+//	Left, h              collapse the node, or move to its parent if it's collapsed
+//	Space                expand or collapse the node
+//	Enter                call OnActivate
+//
+// Clicking a node moves the cursor to it, and clicking its icon expands or collapses it.
+//
+// vxfw has no timer commands, so set PostEvent (typically to [vxfw.App.PostEvent]) to redraw the
+// tree when children finish loading in the background, and to animate the spinner.
+type Tree struct {
+	Root     *Node
+	Provider Provider
+	// ShowRoot shows Root as the first node. Otherwise the children of Root are shown at the
+	// top level, and Root is always expanded.
+	ShowRoot bool
+
+	Style StyleSet
+	// Icons drawn in front of each node
+	ExpandedIcon  string
+	CollapsedIcon string
+	LeafIcon      string
+
+	// OnActivate is called with the node under the cursor when Enter is pressed.
+	OnActivate func(node *Node) (vxfw.Command, error)
+	// PostEvent is used to redraw the tree when children are loaded in the background, and to
+	// animate the spinner.
+	PostEvent func(vaxis.Event)
+
+	// mu guards the children and loading state of the nodes, which providers can change from
+	// other goroutines
+	mu      sync.Mutex
+	ticking bool
+
+	cursor  *Node
+	offset  int
+	visible int
+	rows    []*rowSlot
+	now     func() time.Time
+}
+
+// New returns a [Tree] of the nodes under root, whose children are loaded by provider. The
+// children of root are loaded the first time the tree is drawn.
+func New(root *Node, provider Provider) *Tree {
+	return &Tree{
+		Root:          root,
+		Provider:      provider,
+		ExpandedIcon:  "▾ ",
+		CollapsedIcon: "▸ ",
+		LeafIcon:      "  ",
+		Style: StyleSet{
+			Cursor: vaxis.Style{Attribute: vaxis.AttrReverse},
+			Guide:  vaxis.Style{Foreground: vaxis.IndexColor(8)},
+			Error:  vaxis.Style{Foreground: vaxis.IndexColor(1)},
+		},
+		now: time.Now,
+	}
+}
+
+// entry is a node shown in the tree, and the guide lines in front of it.
+type entry struct {
+	node  *Node
+	guide string
+}
+
+// flatten returns the nodes which are shown, in order. t.mu must be held.
+func (t *Tree) flatten() []entry {
+	if t.Root == nil {
+		return nil
+	}
+
+	var out []entry
+	var walk func(n *Node, top bool, prefix string)
+	walk = func(n *Node, top bool, prefix string) {
+		if !n.expanded && (n != t.Root || t.ShowRoot) {
+			return
+		}
+		for i, c := range n.children {
+			guide, next := prefix+"├─ ", prefix+"│  "
+			if i == len(n.children)-1 {
+				guide, next = prefix+"└─ ", prefix+"   "
+			}
+			if top {
+				guide, next = "", ""
+			}
+			out = append(out, entry{node: c, guide: guide})
+			walk(c, false, next)
+		}
+	}
+
+	if t.ShowRoot {
+		out = append(out, entry{node: t.Root})
+		walk(t.Root, false, "")
+	} else {
+		walk(t.Root, true, "")
+	}
+	return out
+}
+
+// index returns the index of node in entries, or -1.
+func index(entries []entry, node *Node) int {
+	for i, e := range entries {
+		if e.node == node {
+			return i
+		}
+	}
+	return -1
+}
+
+// Cursor returns the node under the cursor, or nil if the tree is empty.
+func (t *Tree) Cursor() *Node {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.validCursor(t.flatten())
+}
+
+// validCursor returns the node under the cursor, moving the cursor to the closest shown ancestor
+// if its node is no longer shown. t.mu must be held.
+func (t *Tree) validCursor(entries []entry) *Node {
+	for n := t.cursor; n != nil; n = n.parent {
+		if index(entries, n) >= 0 {
+			t.cursor = n
+			return n
+		}
+	}
+	t.cursor = nil
+	if len(entries) > 0 {
+		t.cursor = entries[0].node
+	}
+	return t.cursor
+}
+
+// SetCursor moves the cursor to node, expanding its ancestors so it's shown.
+func (t *Tree) SetCursor(node *Node) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for n := node.parent; n != nil; n = n.parent {
+		n.expanded = true
+	}
+	t.cursor = node
+}
+
+// Expand shows the children of node, and loads them if they haven't been loaded.
+func (t *Tree) Expand(node *Node) vxfw.Command {
+	if node.Leaf {
+		return nil
+	}
+	t.mu.Lock()
+	node.expanded = true
+	load := !node.loaded && !node.loading
+	t.mu.Unlock()
+
+	if load {
+		t.load(node)
+	}
+	return vxfw.RedrawCmd{}
+}
+
+// Collapse hides the children of node.
+func (t *Tree) Collapse(node *Node) vxfw.Command {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	node.expanded = false
+	return vxfw.RedrawCmd{}
+}
+
+// Toggle expands node if it's collapsed, and collapses it if it's expanded.
+func (t *Tree) Toggle(node *Node) vxfw.Command {
+	t.mu.Lock()
+	expanded := node.expanded
+	t.mu.Unlock()
+	if expanded {
+		return t.Collapse(node)
+	}
+	return t.Expand(node)
+}
+
+// Reload discards the children of node, and loads them again if node is expanded.
+func (t *Tree) Reload(node *Node) vxfw.Command {
+	t.mu.Lock()
+	node.children, node.loaded, node.err = nil, false, nil
+	load := node.expanded && !node.loading
+	t.mu.Unlock()
+
+	if load {
+		t.load(node)
+	}
+	return vxfw.RedrawCmd{}
+}
+
+// load asks the provider for the children of node. t.mu must not be held, since the provider can
+// call done right away.
+func (t *Tree) load(node *Node) {
+	if t.Provider == nil {
+		t.mu.Lock()
+		node.loaded = true
+		t.mu.Unlock()
+		return
+	}
+
+	t.mu.Lock()
+	node.loading, node.err = true, nil
+	t.mu.Unlock()
+
+	var once sync.Once
+	t.Provider.Children(node, func(children []*Node, err error) {
+		once.Do(func() {
+			t.mu.Lock()
+			node.loading = false
+			if err != nil {
+				node.err = err
+			} else {
+				for _, c := range children {
+					c.parent = node
+				}
+				node.children, node.loaded = children, true
+			}
+			t.mu.Unlock()
+
+			if t.PostEvent != nil {
+				t.PostEvent(vaxis.Redraw{})
+			}
+		})
+	})
+}
+
+// move moves the cursor by delta nodes.
+func (t *Tree) move(delta int) vxfw.Command {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := t.flatten()
+	if len(entries) == 0 {
+		return nil
+	}
+	i := index(entries, t.validCursor(entries)) + delta
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(entries) {
+		i = len(entries) - 1
+	}
+	t.cursor = entries[i].node
+	return vxfw.RedrawCmd{}
+}
+
+func (t *Tree) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Key:
+		if ev.EventType == vaxis.EventRelease {
+			return nil, nil
+		}
+		return t.handleKey(ev)
+	case vaxis.Mouse:
+		if ev.EventType != vaxis.EventPress {
+			return nil, nil
+		}
+		switch ev.Button {
+		case vaxis.MouseWheelUp:
+			t.scroll(-3)
+			return vxfw.ConsumeAndRedraw(), nil
+		case vaxis.MouseWheelDown:
+			t.scroll(3)
+			return vxfw.ConsumeAndRedraw(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *Tree) handleKey(key vaxis.Key) (vxfw.Command, error) {
+	node := t.Cursor()
+	if node == nil {
+		return nil, nil
+	}
+	page := t.visible
+	if page < 1 {
+		page = 1
+	}
+
+	var cmd vxfw.Command
+	switch {
+	case key.Matches(vaxis.KeyUp), key.Matches('k'):
+		cmd = t.move(-1)
+	case key.Matches(vaxis.KeyDown), key.Matches('j'):
+		cmd = t.move(1)
+	case key.Matches(vaxis.KeyPgUp):
+		cmd = t.move(-page)
+	case key.Matches(vaxis.KeyPgDown):
+		cmd = t.move(page)
+	case key.Matches(vaxis.KeyHome), key.Matches('g'):
+		cmd = t.move(-1 << 30)
+	case key.Matches(vaxis.KeyEnd), key.Matches('G'):
+		cmd = t.move(1 << 30)
+	case key.Matches(vaxis.KeyRight), key.Matches('l'):
+		t.mu.Lock()
+		expanded, loaded := node.expanded, node.loaded && len(node.children) > 0
+		t.mu.Unlock()
+		if expanded && loaded {
+			cmd = t.move(1)
+		} else {
+			cmd = t.Expand(node)
+		}
+	case key.Matches(vaxis.KeyLeft), key.Matches('h'):
+		t.mu.Lock()
+		expanded := node.expanded && !node.Leaf
+		parent := node.parent
+		t.mu.Unlock()
+		switch {
+		case expanded:
+			cmd = t.Collapse(node)
+		case parent != nil && (parent != t.Root || t.ShowRoot):
+			t.mu.Lock()
+			t.cursor = parent
+			t.mu.Unlock()
+			cmd = vxfw.RedrawCmd{}
+		}
+	case key.Matches(vaxis.KeySpace):
+		cmd = t.Toggle(node)
+	case key.Matches(vaxis.KeyEnter):
+		if t.OnActivate == nil {
+			return nil, nil
+		}
+		var err error
+		if cmd, err = t.OnActivate(node); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return []vxfw.Command{cmd, vxfw.ConsumeAndRedraw()}, nil
+}
+
+// scroll scrolls the tree by delta rows, keeping the cursor in view.
+func (t *Tree) scroll(delta int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := t.flatten()
+	t.offset = clamp(t.offset+delta, 0, len(entries)-t.visible)
+	i := index(entries, t.validCursor(entries))
+	switch {
+	case i < 0:
+	case i < t.offset:
+		t.cursor = entries[t.offset].node
+	case t.visible > 0 && i >= t.offset+t.visible:
+		t.cursor = entries[t.offset+t.visible-1].node
+	}
+}
+
+var _ vxfw.Widget = &Tree{}
+
+func (t *Tree) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if ctx.Max.HasUnboundedHeight() || ctx.Max.HasUnboundedWidth() {
+		panic("Tree must have bounded constraints")
+	}
+	if t.Root != nil && !t.ShowRoot && !t.Root.expanded {
+		t.Expand(t.Root)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	width, height := ctx.Max.Width, ctx.Max.Height
+	s := vxfw.NewSurface(width, height, t)
+
+	// Keep the cursor in view
+	entries := t.flatten()
+	cursor := index(entries, t.validCursor(entries))
+	t.visible = int(height)
+	if cursor >= 0 && cursor < t.offset {
+		t.offset = cursor
+	}
+	if cursor >= t.offset+t.visible {
+		t.offset = cursor - t.visible + 1
+	}
+	t.offset = clamp(t.offset, 0, len(entries)-t.visible)
+	end := t.offset + t.visible
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+	frame := spinner[int(now().UnixNano()/int64(spinnerInterval))%len(spinner)]
+	loading := false
+	for len(t.rows) < t.visible {
+		t.rows = append(t.rows, &rowSlot{tree: t, icon: &iconSlot{tree: t}})
+	}
+	for i := t.offset; i < end; i++ {
+		e := entries[i]
+		n := e.node
+		row := uint16(i - t.offset)
+
+		style := t.Style.Node
+		guideStyle := t.Style.Guide
+		if n == t.cursor {
+			style, guideStyle = t.Style.Cursor, t.Style.Cursor
+			for col := uint16(0); col < width; col++ {
+				s.WriteCell(col, row, vaxis.Cell{Character: vaxis.Character{Grapheme: " ", Width: 1}, Style: style})
+			}
+		}
+
+		icon := t.CollapsedIcon
+		switch {
+		case n.Leaf:
+			icon = t.LeafIcon
+		case n.loading:
+			icon = frame + " "
+			loading = true
+		case n.expanded:
+			icon = t.ExpandedIcon
+		}
+
+		col := write(ctx, &s, 0, row, width, e.guide, guideStyle)
+		iconStart := col
+		col = write(ctx, &s, col, row, width, icon, style)
+		iconEnd := col
+		col = write(ctx, &s, col, row, width, n.Label, style)
+		if n.err != nil {
+			errStyle := t.Style.Error
+			if n == t.cursor {
+				errStyle = style
+			}
+			write(ctx, &s, col, row, width, " ("+n.err.Error()+")", errStyle)
+		}
+
+		slot := t.rows[i-t.offset]
+		slot.node, slot.icon.node = n, n
+		rowSurface := vxfw.Surface{Size: vxfw.Size{Width: width, Height: 1}, Widget: slot}
+		if iconEnd > iconStart && !n.Leaf {
+			rowSurface.AddChild(int(iconStart), 0, vxfw.Surface{
+				Size:   vxfw.Size{Width: iconEnd - iconStart, Height: 1},
+				Widget: slot.icon,
+			})
+		}
+		s.AddChild(0, int(row), rowSurface)
+	}
+
+	// Keep the spinner turning while children load
+	if loading && t.PostEvent != nil && !t.ticking {
+		t.ticking = true
+		time.AfterFunc(spinnerInterval, func() {
+			t.mu.Lock()
+			t.ticking = false
+			t.mu.Unlock()
+			t.PostEvent(vaxis.Redraw{})
+		})
+	}
+
+	return s, nil
+}
+
+// write writes text to s at col and row, and returns the column after it. Text which doesn't fit
+// in width is truncated with an ellipsis.
+func write(ctx vxfw.DrawContext, s *vxfw.Surface, col, row, width uint16, text string, style vaxis.Style) uint16 {
+	chars := ctx.Characters(text)
+	var total uint16
+	for _, char := range chars {
+		total += uint16(char.Width)
+	}
+
+	fits := col+total <= width
+	for _, char := range chars {
+		w := uint16(char.Width)
+		if !fits && col+w > width-1 {
+			if col < width {
+				s.WriteCell(col, row, vaxis.Cell{Character: vaxis.Character{Grapheme: "…", Width: 1}, Style: style})
+			}
+			return width
+		}
+		s.WriteCell(col, row, vaxis.Cell{Character: char, Style: style})
+		col += w
+	}
+	return col
+}
+
+// rowSlot is the widget on top of a row, which moves the cursor to its node when clicked.
+type rowSlot struct {
+	tree *Tree
+	node *Node
+	icon *iconSlot
+}
+
+func (r *rowSlot) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	return vxfw.Surface{Size: vxfw.Size{Width: ctx.Max.Width, Height: 1}, Widget: r}, nil
+}
+
+func (r *rowSlot) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok || mouse.EventType != vaxis.EventPress || mouse.Button != vaxis.MouseLeftButton {
+		return nil, nil
+	}
+	r.tree.mu.Lock()
+	r.tree.cursor = r.node
+	r.tree.mu.Unlock()
+	return []vxfw.Command{vxfw.FocusWidgetCmd(r.tree), vxfw.ConsumeAndRedraw()}, nil
+}
+
+// iconSlot is the widget on top of the icon of a node, which expands or collapses it when clicked.
+type iconSlot struct {
+	tree *Tree
+	node *Node
+}
+
+func (i *iconSlot) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	return vxfw.Surface{Size: vxfw.Size{Width: ctx.Max.Width, Height: 1}, Widget: i}, nil
+}
+
+func (i *iconSlot) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	switch ev := ev.(type) {
+	case vaxis.Mouse:
+		if ev.EventType != vaxis.EventPress || ev.Button != vaxis.MouseLeftButton {
+			return nil, nil
+		}
+		i.tree.mu.Lock()
+		i.tree.cursor = i.node
+		i.tree.mu.Unlock()
+		cmd := i.tree.Toggle(i.node)
+		return []vxfw.Command{cmd, vxfw.FocusWidgetCmd(i.tree), vxfw.ConsumeAndRedraw()}, nil
+	case vxfw.MouseEnter:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeClickable), nil
+	case vxfw.MouseLeave:
+		return vxfw.SetMouseShapeCmd(vaxis.MouseShapeDefault), nil
+	}
+	return nil, nil
+}
+
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
@@ -0,0 +1,189 @@
+package tree
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// rows returns the graphemes of each row of s, with trailing blanks removed.
+func rows(s vxfw.Surface) []string {
+	out := make([]string, s.Size.Height)
+	for i, cell := range s.Buffer {
+		g := cell.Grapheme
+		if g == "" {
+			g = " "
+		}
+		out[i/int(s.Size.Width)] += g
+	}
+	for i := range out {
+		out[i] = strings.TrimRight(out[i], " ")
+	}
+	return out
+}
+
+func draw(t *testing.T, tree *Tree, height uint16) []string {
+	t.Helper()
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: height}, Characters: vaxis.Characters}
+	s, err := tree.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows(s)
+}
+
+func compare(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Logf("wrong %s, got=%q, want=%q", name, got, want)
+			t.Fail()
+			return
+		}
+	}
+}
+
+func press(t *testing.T, tree *Tree, keys ...rune) {
+	t.Helper()
+	for _, key := range keys {
+		if _, err := tree.HandleEvent(vaxis.Key{Keycode: key}, vxfw.TargetPhase); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// dirs is a provider of a fake file system, where nodes named d* are directories.
+var dirs = ProviderFunc(func(node *Node) ([]*Node, error) {
+	prefix := node.Label + "/"
+	if node.Value == nil {
+		prefix = ""
+	}
+	return []*Node{
+		NewNode(prefix+"d", true),
+		NewLeaf(prefix+"f", true),
+	}, nil
+})
+
+func TestTree(t *testing.T) {
+	tree := New(NewNode("root", nil), dirs)
+
+	compare(t, "top level", draw(t, tree, 5), []string{"▸ d", "  f"})
+
+	// Right expands, then moves to the first child
+	press(t, tree, vaxis.KeyRight, vaxis.KeyRight, vaxis.KeyRight)
+	compare(t, "expanded", draw(t, tree, 5), []string{
+		"▾ d",
+		"├─ ▾ d/d",
+		"│  ├─ ▸ d/d/d",
+		"│  └─   d/d/f",
+		"└─   d/f",
+	})
+	if got := tree.Cursor().Label; got != "d/d" {
+		t.Logf("wrong cursor, got=%q, want=%q", got, "d/d")
+		t.Fail()
+	}
+
+	// Left collapses, then moves to the parent
+	press(t, tree, vaxis.KeyLeft, vaxis.KeyLeft)
+	if got := tree.Cursor().Label; got != "d" {
+		t.Logf("wrong cursor, got=%q, want=%q", got, "d")
+		t.Fail()
+	}
+	compare(t, "collapsed", draw(t, tree, 5), []string{"▾ d", "├─ ▸ d/d", "└─   d/f", "  f"})
+}
+
+// async is a provider which loads children when the test sends them.
+type async struct {
+	done chan func([]*Node, error)
+}
+
+func (a *async) Children(node *Node, done func([]*Node, error)) {
+	go func() { a.done <- done }()
+}
+
+func TestTreeAsync(t *testing.T) {
+	provider := &async{done: make(chan func([]*Node, error))}
+	redraws := make(chan vaxis.Event, 10)
+	tree := New(NewNode("root", nil), provider)
+	tree.ShowRoot = true
+	tree.PostEvent = func(ev vaxis.Event) { redraws <- ev }
+	tree.now = func() time.Time { return time.Unix(0, 0) }
+
+	press(t, tree, vaxis.KeySpace)
+	compare(t, "loading", draw(t, tree, 3), []string{"⠋ root"})
+
+	done := <-provider.done
+	done(nil, errors.New("denied"))
+	<-redraws
+	compare(t, "error", draw(t, tree, 3), []string{"▾ root (denied)"})
+
+	// Expanding again retries
+	press(t, tree, vaxis.KeySpace, vaxis.KeySpace)
+	done = <-provider.done
+	done([]*Node{NewLeaf("a", nil)}, nil)
+	<-redraws
+	compare(t, "loaded", draw(t, tree, 3), []string{"▾ root", "└─   a"})
+}
+
+// click delivers a left button press at col, row to the deepest widget of s under it, and then to
+// the widgets around it until one consumes it, as the application does.
+func click(t *testing.T, s vxfw.Surface, col, row int) {
+	t.Helper()
+	var hits []vxfw.Widget
+	for {
+		hits = append(hits, s.Widget)
+		found := false
+		for _, child := range s.Children {
+			c, r := col-child.Origin.Col, row-child.Origin.Row
+			if c >= 0 && r >= 0 && c < int(child.Surface.Size.Width) && r < int(child.Surface.Size.Height) {
+				s, col, row, found = child.Surface, c, r, true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	ev := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventPress}
+	for i := len(hits) - 1; i >= 0; i-- {
+		h, ok := hits[i].(vxfw.EventHandler)
+		if !ok {
+			continue
+		}
+		cmd, err := h.HandleEvent(ev, vxfw.BubblePhase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cmds, ok := cmd.([]vxfw.Command); ok && len(cmds) > 0 {
+			return
+		}
+	}
+}
+
+func TestTreeClick(t *testing.T) {
+	tree := New(NewNode("root", nil), dirs)
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: 5}, Characters: vaxis.Characters}
+	s, err := tree.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Clicking the label of a node moves the cursor to it, without expanding it
+	click(t, s, 2, 1)
+	click(t, s, 2, 0)
+	if n := tree.Cursor(); n.Label != "d" || n.Expanded() {
+		t.Logf("wrong result of clicking a label, cursor=%q expanded=%v", n.Label, n.Expanded())
+		t.Fail()
+	}
+
+	// Clicking its icon expands it
+	click(t, s, 0, 0)
+	if !tree.Cursor().Expanded() {
+		t.Log("clicking the icon didn't expand the node")
+		t.Fail()
+	}
+}
@@ -1,6 +1,14 @@
+// Package vxexp is a collection of experimental widgets and utilities for [vxfw].
+//
+// Values provided with [Provide] are carried down the tree by the Characters function of the
+// [vxfw.DrawContext]. Widgets which draw their children with a context of their own making must
+// copy Characters from their own context, and widgets which replace it must pass on the calls they
+// don't handle themselves, or the values provided above them are lost.
 package vxexp
 
 import (
+	"sort"
+
 	"git.sr.ht/~rockorager/vaxis"
 	"git.sr.ht/~rockorager/vaxis/vxfw"
 )
@@ -52,3 +60,56 @@ func TightenContext(ctx vxfw.DrawContext, size vxfw.Size) vxfw.DrawContext {
 func LoosenContext(ctx vxfw.DrawContext) vxfw.DrawContext {
 	return ctx.WithMin(vxfw.Size{})
 }
+
+// Flatten renders s and all of its children, in z-order, into a single surface with the same size
+// and widget as s. Children are clipped to the bounds of their parent.
+// The result has no children, so no widget within s can receive focus or mouse events.
+func Flatten(s vxfw.Surface) vxfw.Surface {
+	out := vxfw.NewSurface(s.Size.Width, s.Size.Height, s.Widget)
+	flatten(&out, s, 0, 0, clip{right: int(s.Size.Width), bottom: int(s.Size.Height)})
+	return out
+}
+
+// clip is a rectangle in the coordinates of a flattened surface, with exclusive right and bottom
+// edges.
+type clip struct {
+	left, top, right, bottom int
+}
+
+// flatten writes s into out at col, row. Only cells within c are written.
+func flatten(out *vxfw.Surface, s vxfw.Surface, col, row int, c clip) {
+	// Intersect the clip with the bounds of s
+	if col > c.left {
+		c.left = col
+	}
+	if row > c.top {
+		c.top = row
+	}
+	if end := col + int(s.Size.Width); end < c.right {
+		c.right = end
+	}
+	if end := row + int(s.Size.Height); end < c.bottom {
+		c.bottom = end
+	}
+	if c.left >= c.right || c.top >= c.bottom {
+		return
+	}
+
+	for i, cell := range s.Buffer {
+		x, y := col+i%int(s.Size.Width), row+i/int(s.Size.Width)
+		if x < c.left || x >= c.right || y < c.top || y >= c.bottom {
+			continue
+		}
+		out.WriteCell(uint16(x), uint16(y), cell)
+	}
+
+	children := make([]vxfw.SubSurface, len(s.Children))
+	copy(children, s.Children)
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].ZIndex < children[j].ZIndex
+	})
+
+	for _, child := range children {
+		flatten(out, child.Surface, col+child.Origin.Col, row+child.Origin.Row, c)
+	}
+}
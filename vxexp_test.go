@@ -0,0 +1,214 @@
+package vxexp
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+func cell(g string) vaxis.Cell {
+	return vaxis.Cell{Character: vaxis.Character{Grapheme: g, Width: 1}}
+}
+
+func TestFlatten(t *testing.T) {
+	root := vxfw.NewSurface(4, 2, nil)
+	root.Fill(cell("."))
+
+	// low is partially outside of root, and high overlaps it with a higher z-index
+	low := vxfw.NewSurface(3, 1, nil)
+	low.Fill(cell("a"))
+	high := vxfw.NewSurface(1, 2, nil)
+	high.Fill(cell("b"))
+
+	hs := vxfw.NewSubSurface(2, 0, high)
+	hs.ZIndex = 1
+	root.Children = append(root.Children, hs)
+	root.AddChild(-1, 1, low)
+
+	flat := Flatten(root)
+	if len(flat.Children) != 0 {
+		t.Fatalf("flattened surface has children")
+	}
+
+	want := "..b.aab."
+	got := ""
+	for _, c := range flat.Buffer {
+		got += c.Grapheme
+	}
+	if got != want {
+		t.Logf("wrong flattened content, got=%q, want=%q", got, want)
+		t.Fail()
+	}
+}
+
+type locale string
+
+type flags struct{ beta bool }
+
+type named interface{ Name() string }
+
+type user struct{ name string }
+
+func (u user) Name() string { return u.name }
+
+func TestProvide(t *testing.T) {
+	var (
+		gotLocale []locale
+		gotFlags  *flags
+		gotNamed  named
+		last      vxfw.DrawContext
+	)
+	leaf := WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		l, _ := Lookup[locale](ctx)
+		gotLocale = append(gotLocale, l)
+		gotFlags, _ = Lookup[*flags](ctx)
+		gotNamed, _ = Lookup[named](ctx)
+		last = ctx
+		return vxfw.NewSurface(1, 1, nil), nil
+	})
+
+	beta := &flags{beta: true}
+	tree := Provide(locale("en"), Provide(beta, WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		if _, err := leaf.Draw(ctx.WithMax(vxfw.Size{Width: 5, Height: 5})); err != nil {
+			return vxfw.Surface{}, err
+		}
+		// An inner provider shadows an outer one of the same type, and can derive its value
+		// from it
+		inner := ProvideFunc(func(ctx vxfw.DrawContext) locale {
+			outer, _ := Lookup[locale](ctx)
+			return outer + "-GB"
+		}, Provide(user{name: "ada"}, leaf))
+		return inner.Draw(ctx)
+	})))
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 10, Height: 10}, Characters: vaxis.Characters}
+	s, err := tree.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Size != (vxfw.Size{Width: 1, Height: 1}) {
+		t.Logf("wrong provider size, got=%v", s.Size)
+		t.Fail()
+	}
+
+	if len(gotLocale) != 2 || gotLocale[0] != "en" || gotLocale[1] != "en-GB" {
+		t.Logf("wrong locales, got=%v, want=[en en-GB]", gotLocale)
+		t.Fail()
+	}
+	if gotFlags != beta {
+		t.Logf("wrong flags, got=%v, want=%v", gotFlags, beta)
+		t.Fail()
+	}
+	if gotNamed == nil || gotNamed.Name() != "ada" {
+		t.Logf("wrong value for an interface type, got=%v", gotNamed)
+		t.Fail()
+	}
+
+	// A retained context still finds its values, for use while handling events
+	if l, ok := Lookup[locale](last); !ok || l != "en-GB" {
+		t.Logf("wrong locale from a retained context, got=%q, %v", l, ok)
+		t.Fail()
+	}
+
+	// Nothing is found without a provider
+	if _, ok := Lookup[locale](ctx); ok {
+		t.Logf("found a value without a provider")
+		t.Fail()
+	}
+	if n := len(last.Characters("abc")); n != 3 {
+		t.Logf("wrong number of characters below a provider, got=%d, want=3", n)
+		t.Fail()
+	}
+}
+
+func TestLookupWithoutProvider(t *testing.T) {
+	var measured []string
+	ctx := vxfw.DrawContext{Characters: func(s string) []vaxis.Character {
+		measured = append(measured, s)
+		return vaxis.Characters(s)
+	}}
+
+	// A lookup which no provider answers doesn't measure anything
+	if _, ok := Lookup[locale](ctx); ok {
+		t.Logf("found a value without a provider")
+		t.Fail()
+	}
+	for _, s := range measured {
+		if s != "" {
+			t.Logf("lookup measured %q", s)
+			t.Fail()
+		}
+	}
+
+	// Below a provider, measuring the empty string outside of a lookup is not a lookup
+	var below vxfw.DrawContext
+	tree := Provide(locale("en"), WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		below = ctx
+		return vxfw.NewSurface(1, 1, nil), nil
+	}))
+	if _, err := tree.Draw(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(below.Characters("")); n != 0 {
+		t.Logf("wrong number of characters in the empty string, got=%d, want=0", n)
+		t.Fail()
+	}
+}
+
+func TestMouseRegion(t *testing.T) {
+	var events []string
+	r := MouseRegion(WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		return vxfw.NewSurface(2, 1, nil), nil
+	}), func() {
+		events = append(events, "enter")
+	}, func() {
+		events = append(events, "exit")
+	}, func(vaxis.Mouse) {
+		events = append(events, "hover")
+	})
+	draw := func() {
+		if _, err := r.Draw(vxfw.DrawContext{Max: vxfw.Size{Width: 2, Height: 1}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(name string, hovered bool, want ...string) {
+		t.Helper()
+		if len(events) != len(want) {
+			t.Logf("%s: wrong events, got=%v, want=%v", name, events, want)
+			t.Fail()
+		} else {
+			for i := range want {
+				if events[i] != want[i] {
+					t.Logf("%s: wrong events, got=%v, want=%v", name, events, want)
+					t.Fail()
+					break
+				}
+			}
+		}
+		if r.Hovered() != hovered {
+			t.Logf("%s: wrong hovered, got=%v, want=%v", name, r.Hovered(), hovered)
+			t.Fail()
+		}
+		events = nil
+	}
+
+	// The mouse moves onto the region
+	r.HandleEvent(vxfw.MouseEnter{}, vxfw.TargetPhase)
+	r.CaptureEvent(vaxis.Mouse{EventType: vaxis.EventMotion})
+	draw()
+	check("enter", true, "enter", "hover")
+
+	// and within it, which vxfw sends as leaving and entering again
+	r.HandleEvent(vxfw.MouseLeave{}, vxfw.TargetPhase)
+	r.HandleEvent(vxfw.MouseEnter{}, vxfw.TargetPhase)
+	r.CaptureEvent(vaxis.Mouse{EventType: vaxis.EventMotion})
+	draw()
+	check("move", true, "hover")
+
+	// and off it
+	r.HandleEvent(vxfw.MouseLeave{}, vxfw.TargetPhase)
+	check("leave before drawing", false)
+	draw()
+	check("leave", false, "exit")
+}
@@ -1,7 +1,8 @@
 package vxlayout
 
 import (
-	"git.sr.ht/~rockorager/vaxis"
+	"math"
+
 	"git.sr.ht/~rockorager/vaxis/vxfw"
 	"github.com/avidal/vxexp"
 )
@@ -57,13 +58,35 @@ func Limited(widget vxfw.Widget, size vxfw.Size) vxfw.Widget {
 	})
 }
 
-// Fill returns a [vxfw.Widget] that fills its space with the supplied cell.
-// Note that Fill will take all available space. It's primarily useful to diagnose layouts, and
-// will usually be contained in a [Flexible]
-func Fill(cell vaxis.Cell) vxfw.Widget {
+// Fraction is a [vxfw.Widget] that sizes its child to a fraction of the incoming max constraints.
+// For example, Fraction(dialog, 0.6, 0.4) gives dialog 60% of the available width and 40% of the
+// available height. Factors are clamped to [0, 1], and the resulting size never goes below the
+// incoming min constraint.
+// If a factor is 0, or the incoming max is unbounded on that axis, that axis is left as is and the
+// child takes its intrinsic size. Wrap the child in [Limited] as well to bound it in that case.
+func Fraction(widget vxfw.Widget, widthFactor, heightFactor float64) vxfw.Widget {
 	return vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
-		surface := vxfw.NewSurface(ctx.Max.Width, ctx.Max.Height, nil)
-		surface.Fill(cell)
-		return surface, nil
+		if widthFactor > 0 && !ctx.Max.HasUnboundedWidth() {
+			w := fraction(ctx.Min.Width, ctx.Max.Width, widthFactor)
+			ctx.Min.Width, ctx.Max.Width = w, w
+		}
+		if heightFactor > 0 && !ctx.Max.HasUnboundedHeight() {
+			h := fraction(ctx.Min.Height, ctx.Max.Height, heightFactor)
+			ctx.Min.Height, ctx.Max.Height = h, h
+		}
+
+		return widget.Draw(ctx)
 	})
 }
+
+// fraction returns factor * max, rounded to the nearest cell and clamped to [min, max].
+func fraction(min, max uint16, factor float64) uint16 {
+	if factor > 1 {
+		factor = 1
+	}
+	size := uint16(math.Round(float64(max) * factor))
+	if size < min {
+		return min
+	}
+	return size
+}
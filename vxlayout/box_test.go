@@ -0,0 +1,39 @@
+package vxlayout
+
+import (
+	"math"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+)
+
+func TestFraction(t *testing.T) {
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: 10}, Characters: vaxis.Characters}
+
+	surface, err := Fraction(Fill(vaxis.Cell{}), 0.6, 0.45).Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 0.45 * 10 rounds up to 5
+	want := vxfw.Size{Width: 12, Height: 5}
+	if surface.Size != want {
+		t.Logf("wrong fraction size, got=%v, want=%v", surface.Size, want)
+		t.Fail()
+	}
+
+	// An unbounded axis is left alone, so the child takes its intrinsic height
+	ctx.Max.Height = math.MaxUint16
+	surface, err = Fraction(text.New("abc"), 0.5, 0.5).Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want = vxfw.Size{Width: 10, Height: 1}
+	if surface.Size != want {
+		t.Logf("wrong fraction size with unbounded height, got=%v, want=%v", surface.Size, want)
+		t.Fail()
+	}
+}
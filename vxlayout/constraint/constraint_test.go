@@ -0,0 +1,134 @@
+package constraint
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+)
+
+func TestSolverStrengths(t *testing.T) {
+	x := NewVariable("x")
+	y := NewVariable("y")
+
+	s := NewSolver()
+	constraints := []*Constraint{
+		x.Add(y).Eq(Constant(20)),
+		x.GreaterEq(Constant(5)),
+		// x == 15 is stronger than x == y, so y takes whatever is left
+		x.Eq(Constant(15)).WithStrength(Strong),
+		x.Eq(y).WithStrength(Weak),
+	}
+	for _, c := range constraints {
+		if err := s.AddConstraint(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.UpdateVariables()
+
+	if x.Value() != 15 || y.Value() != 5 {
+		t.Logf("wrong solution, got x=%v y=%v, want x=15 y=5", x.Value(), y.Value())
+		t.Fail()
+	}
+
+	// Removing the strong constraint lets the weak one take over
+	if err := s.RemoveConstraint(constraints[2]); err != nil {
+		t.Fatal(err)
+	}
+	s.UpdateVariables()
+
+	if x.Value() != 10 || y.Value() != 10 {
+		t.Logf("wrong solution, got x=%v y=%v, want x=10 y=10", x.Value(), y.Value())
+		t.Fail()
+	}
+}
+
+func TestSolverUnsatisfiable(t *testing.T) {
+	x := NewVariable("x")
+
+	s := NewSolver()
+	if err := s.AddConstraint(x.GreaterEq(Constant(10))); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.AddConstraint(x.LessEq(Constant(5)))
+	if !errors.Is(err, ErrUnsatisfiable) {
+		t.Fatalf("wrong error, got=%v, want=%v", err, ErrUnsatisfiable)
+	}
+
+	// A failed constraint must not change the solution
+	s.UpdateVariables()
+	if x.Value() != 10 {
+		t.Logf("wrong solution, got x=%v, want x=10", x.Value())
+		t.Fail()
+	}
+}
+
+func TestLayout(t *testing.T) {
+	l := New()
+	a := l.Add(text.New("abc"))
+	b := l.Add(text.New("def"))
+	l.Constrain(
+		a.Left.Eq(l.Left),
+		a.Width.GreaterEq(Constant(6)),
+		a.Right().Offset(1).Eq(b.Left),
+		b.Right().Eq(l.Right()),
+		b.Top.Eq(a.Bottom()),
+	)
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 16, Height: 4}, Characters: vaxis.Characters}
+	surface, err := l.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if surface.Size != ctx.Max {
+		t.Logf("wrong layout size, got=%v, want=%v", surface.Size, ctx.Max)
+		t.Fail()
+	}
+
+	want := []struct {
+		origin vxfw.RelativePoint
+		size   vxfw.Size
+	}{
+		{vxfw.RelativePoint{Row: 0, Col: 0}, vxfw.Size{Width: 6, Height: 1}},
+		// b is stretched between a and the right edge
+		{vxfw.RelativePoint{Row: 1, Col: 7}, vxfw.Size{Width: 9, Height: 1}},
+	}
+
+	for i, w := range want {
+		child := surface.Children[i]
+		if child.Origin != w.origin {
+			t.Logf("wrong origin for child %d, got=%v, want=%v", i, child.Origin, w.origin)
+			t.Fail()
+		}
+		if child.Surface.Size != w.size {
+			t.Logf("wrong size for child %d, got=%v, want=%v", i, child.Surface.Size, w.size)
+			t.Fail()
+		}
+	}
+}
+
+func TestLayoutUnbounded(t *testing.T) {
+	l := New()
+	a := l.Add(text.New("abc"))
+	b := l.Add(text.New("def"))
+	l.Constrain(a.Right().Offset(2).Eq(b.Left))
+
+	ctx := vxfw.DrawContext{
+		Max:        vxfw.Size{Width: math.MaxUint16, Height: 4},
+		Characters: vaxis.Characters,
+	}
+	surface, err := l.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if surface.Size.Width != 8 {
+		t.Logf("wrong layout width, got=%d, want=8", surface.Size.Width)
+		t.Fail()
+	}
+}
@@ -0,0 +1,221 @@
+package constraint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Strength is the priority of a [Constraint]. The solver will always satisfy a [Required]
+// constraint (or fail), and otherwise tries to satisfy stronger constraints before weaker ones.
+type Strength float64
+
+// NewStrength returns a [Strength] made up of strong, medium and weak components, each of which
+// is clamped to [0, 1000]. A single unit of a stronger component outweighs any amount of a weaker
+// one.
+func NewStrength(strong, medium, weak float64) Strength {
+	clamp := func(x float64) float64 {
+		switch {
+		case x < 0:
+			return 0
+		case x > 1000:
+			return 1000
+		default:
+			return x
+		}
+	}
+	return Strength(clamp(strong)*1000000 + clamp(medium)*1000 + clamp(weak))
+}
+
+var (
+	Required = NewStrength(1000, 1000, 1000)
+	Strong   = NewStrength(1, 0, 0)
+	Medium   = NewStrength(0, 1, 0)
+	Weak     = NewStrength(0, 0, 1)
+)
+
+// clip clamps s so that no non-required constraint can outweigh a required one.
+func (s Strength) clip() Strength {
+	switch {
+	case s < 0:
+		return 0
+	case s > Required:
+		return Required
+	default:
+		return s
+	}
+}
+
+// Operand is anything that can be used on either side of a [Constraint]: a [*Variable], an
+// [Expression] or a [Constant].
+type Operand interface {
+	Expression() Expression
+}
+
+// Constant is an [Operand] with a fixed value.
+type Constant float64
+
+// Expression implements [Operand]
+func (c Constant) Expression() Expression {
+	return Expression{Constant: float64(c)}
+}
+
+// Variable is a value which is computed by a [Solver].
+type Variable struct {
+	Name  string
+	value float64
+}
+
+// NewVariable returns a new [Variable]. The name is only used for debugging.
+func NewVariable(name string) *Variable {
+	return &Variable{Name: name}
+}
+
+// Value returns the value most recently computed by a [Solver].
+func (v *Variable) Value() float64 { return v.value }
+
+// Expression implements [Operand]
+func (v *Variable) Expression() Expression {
+	return Expression{Terms: []Term{{Variable: v, Coefficient: 1}}}
+}
+
+func (v *Variable) Add(o Operand) Expression        { return v.Expression().Add(o) }
+func (v *Variable) Sub(o Operand) Expression        { return v.Expression().Sub(o) }
+func (v *Variable) Offset(c float64) Expression     { return v.Expression().Offset(c) }
+func (v *Variable) Scale(c float64) Expression      { return v.Expression().Scale(c) }
+func (v *Variable) Eq(o Operand) *Constraint        { return v.Expression().Eq(o) }
+func (v *Variable) LessEq(o Operand) *Constraint    { return v.Expression().LessEq(o) }
+func (v *Variable) GreaterEq(o Operand) *Constraint { return v.Expression().GreaterEq(o) }
+
+// Term is a [Variable] multiplied by a coefficient.
+type Term struct {
+	Variable    *Variable
+	Coefficient float64
+}
+
+// Expression is a linear combination of variables plus a constant.
+type Expression struct {
+	Terms    []Term
+	Constant float64
+}
+
+// Expression implements [Operand]
+func (e Expression) Expression() Expression { return e }
+
+// Add returns the sum of e and o.
+func (e Expression) Add(o Operand) Expression {
+	other := o.Expression()
+	terms := make([]Term, 0, len(e.Terms)+len(other.Terms))
+	terms = append(terms, e.Terms...)
+	terms = append(terms, other.Terms...)
+	return Expression{Terms: terms, Constant: e.Constant + other.Constant}
+}
+
+// Sub returns e minus o.
+func (e Expression) Sub(o Operand) Expression {
+	return e.Add(o.Expression().Scale(-1))
+}
+
+// Offset returns e with c added to its constant.
+func (e Expression) Offset(c float64) Expression {
+	return Expression{Terms: e.Terms, Constant: e.Constant + c}
+}
+
+// Scale returns e multiplied by c.
+func (e Expression) Scale(c float64) Expression {
+	terms := make([]Term, len(e.Terms))
+	for i, t := range e.Terms {
+		terms[i] = Term{Variable: t.Variable, Coefficient: t.Coefficient * c}
+	}
+	return Expression{Terms: terms, Constant: e.Constant * c}
+}
+
+// Eq returns a required [Constraint] that e == o.
+func (e Expression) Eq(o Operand) *Constraint { return newConstraint(e, o, OpEq) }
+
+// LessEq returns a required [Constraint] that e <= o.
+func (e Expression) LessEq(o Operand) *Constraint { return newConstraint(e, o, OpLessEq) }
+
+// GreaterEq returns a required [Constraint] that e >= o.
+func (e Expression) GreaterEq(o Operand) *Constraint { return newConstraint(e, o, OpGreaterEq) }
+
+func (e Expression) String() string {
+	var b strings.Builder
+	for i, t := range e.Terms {
+		if i > 0 {
+			b.WriteString(" + ")
+		}
+		if t.Coefficient != 1 {
+			fmt.Fprintf(&b, "%g*", t.Coefficient)
+		}
+		b.WriteString(t.Variable.Name)
+	}
+	if e.Constant != 0 || len(e.Terms) == 0 {
+		if len(e.Terms) > 0 {
+			b.WriteString(" + ")
+		}
+		fmt.Fprintf(&b, "%g", e.Constant)
+	}
+	return b.String()
+}
+
+// reduce combines the terms of e that share a variable.
+func (e Expression) reduce() Expression {
+	coeffs := make(map[*Variable]float64, len(e.Terms))
+	order := make([]*Variable, 0, len(e.Terms))
+	for _, t := range e.Terms {
+		if _, ok := coeffs[t.Variable]; !ok {
+			order = append(order, t.Variable)
+		}
+		coeffs[t.Variable] += t.Coefficient
+	}
+
+	out := Expression{Terms: make([]Term, 0, len(order)), Constant: e.Constant}
+	for _, v := range order {
+		out.Terms = append(out.Terms, Term{Variable: v, Coefficient: coeffs[v]})
+	}
+	return out
+}
+
+// Operator is the relation between both sides of a [Constraint].
+type Operator int
+
+const (
+	OpEq Operator = iota
+	OpLessEq
+	OpGreaterEq
+)
+
+// Constraint is a linear relation between two expressions, with a [Strength].
+// A constraint is stored as a single expression related to 0, ie: lhs - rhs <op> 0.
+type Constraint struct {
+	expr     Expression
+	op       Operator
+	strength Strength
+}
+
+func newConstraint(lhs Expression, rhs Operand, op Operator) *Constraint {
+	return &Constraint{
+		expr:     lhs.Sub(rhs).reduce(),
+		op:       op,
+		strength: Required,
+	}
+}
+
+// WithStrength returns a copy of c with the given strength.
+func (c *Constraint) WithStrength(s Strength) *Constraint {
+	return &Constraint{expr: c.expr, op: c.op, strength: s.clip()}
+}
+
+func (c *Constraint) String() string {
+	op := "=="
+	switch c.op {
+	case OpLessEq:
+		op = "<="
+	case OpGreaterEq:
+		op = ">="
+	}
+	return fmt.Sprintf("%s %s 0", c.expr, op)
+}
+
+// Strength returns the strength of c.
+func (c *Constraint) Strength() Strength { return c.strength }
@@ -0,0 +1,209 @@
+// Package constraint provides a Cassowary linear constraint [Solver] and a [Layout] widget that
+// positions its children by solving constraints between their edges and sizes.
+//
+// Layout is useful when children need to be aligned with each other in ways that can't be
+// expressed by nesting Rows and Columns, for example aligning widgets in distant subtrees:
+//
+//	l := constraint.New()
+//	a := l.Add(sidebar)
+//	b := l.Add(content)
+//	l.Constrain(
+//		a.Right().Offset(1).Eq(b.Left),
+//		a.Width.GreaterEq(constraint.Constant(20)),
+//		a.Width.Eq(l.Width.Scale(0.25)).WithStrength(constraint.Medium),
+//		b.Right().Eq(l.Right()),
+//	)
+package constraint
+
+import (
+	"fmt"
+	"math"
+
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// weakest is used for the default preferences of a [Layout] which should never win over a
+// preference set by the caller.
+var weakest = NewStrength(0, 0, 0.001)
+
+// Box is a rectangle described by solver variables.
+type Box struct {
+	Left   *Variable
+	Top    *Variable
+	Width  *Variable
+	Height *Variable
+}
+
+func newBox(name string) Box {
+	return Box{
+		Left:   NewVariable(name + ".left"),
+		Top:    NewVariable(name + ".top"),
+		Width:  NewVariable(name + ".width"),
+		Height: NewVariable(name + ".height"),
+	}
+}
+
+// Right returns an expression for the right edge of b. Like [vxfw.Surface] sizes, the right edge
+// is exclusive: a box at left 0 with a width of 4 has a right edge of 4.
+func (b Box) Right() Expression { return b.Left.Add(b.Width) }
+
+// Bottom returns an expression for the (exclusive) bottom edge of b.
+func (b Box) Bottom() Expression { return b.Top.Add(b.Height) }
+
+// CenterX returns an expression for the horizontal center of b.
+func (b Box) CenterX() Expression { return b.Left.Add(b.Width.Scale(0.5)) }
+
+// CenterY returns an expression for the vertical center of b.
+func (b Box) CenterY() Expression { return b.Top.Add(b.Height.Scale(0.5)) }
+
+// Child is a widget in a [Layout] along with the [Box] it will be placed in.
+type Child struct {
+	Box
+	Widget vxfw.Widget
+}
+
+// Layout is a [vxfw.Widget] which positions its children according to a set of constraints.
+// The embedded [Box] refers to the layout itself, with Left and Top always 0.
+//
+// Each child is given a [Weak] preference for its intrinsic size, a [Strong] preference to stay
+// within the bounds of the layout, and an even weaker preference to be placed at the top left.
+// Any other relations have to be provided by the caller with [Layout.Constrain].
+// If the incoming width or height is unbounded, the layout sizes itself to fit its children on
+// that axis.
+type Layout struct {
+	Box
+
+	children    []*Child
+	constraints []*Constraint
+}
+
+// New returns an empty [Layout].
+func New() *Layout {
+	return &Layout{Box: newBox("layout")}
+}
+
+// Add adds widget to the layout and returns the [Child] used to constrain it.
+func (l *Layout) Add(widget vxfw.Widget) *Child {
+	child := &Child{
+		Box:    newBox(fmt.Sprintf("child%d", len(l.children))),
+		Widget: widget,
+	}
+	l.children = append(l.children, child)
+	return child
+}
+
+// Constrain adds constraints to the layout.
+func (l *Layout) Constrain(constraints ...*Constraint) {
+	l.constraints = append(l.constraints, constraints...)
+}
+
+var _ vxfw.Widget = &Layout{}
+
+func (l *Layout) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	solver := NewSolver()
+	add := func(c *Constraint) error {
+		if err := solver.AddConstraint(c); err != nil {
+			return fmt.Errorf("%w: %s", err, c)
+		}
+		return nil
+	}
+
+	// The layout itself is always placed at the origin, and fills the max constraint unless
+	// it's unbounded.
+	base := []*Constraint{
+		l.Left.Eq(Constant(0)),
+		l.Top.Eq(Constant(0)),
+		l.Width.GreaterEq(Constant(float64(ctx.Min.Width))),
+		l.Height.GreaterEq(Constant(float64(ctx.Min.Height))),
+	}
+	if ctx.Max.HasUnboundedWidth() {
+		base = append(base, l.Width.Eq(Constant(0)).WithStrength(weakest))
+	} else {
+		base = append(base, l.Width.Eq(Constant(float64(ctx.Max.Width))))
+	}
+	if ctx.Max.HasUnboundedHeight() {
+		base = append(base, l.Height.Eq(Constant(0)).WithStrength(weakest))
+	} else {
+		base = append(base, l.Height.Eq(Constant(float64(ctx.Max.Height))))
+	}
+
+	for _, child := range l.children {
+		// Children are measured with a loose constraint to determine their preferred size
+		intrinsic, err := child.Widget.Draw(ctx.WithMin(vxfw.Size{}))
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+
+		base = append(base,
+			child.Left.GreaterEq(Constant(0)),
+			child.Top.GreaterEq(Constant(0)),
+			child.Width.GreaterEq(Constant(0)),
+			child.Height.GreaterEq(Constant(0)),
+			child.Right().LessEq(l.Width).WithStrength(Strong),
+			child.Bottom().LessEq(l.Height).WithStrength(Strong),
+			child.Width.Eq(Constant(float64(intrinsic.Size.Width))).WithStrength(Weak),
+			child.Height.Eq(Constant(float64(intrinsic.Size.Height))).WithStrength(Weak),
+			child.Left.Eq(Constant(0)).WithStrength(weakest),
+			child.Top.Eq(Constant(0)).WithStrength(weakest),
+		)
+
+		// An unbounded layout grows to fit its children
+		if ctx.Max.HasUnboundedWidth() {
+			base = append(base, l.Width.GreaterEq(child.Right()))
+		}
+		if ctx.Max.HasUnboundedHeight() {
+			base = append(base, l.Height.GreaterEq(child.Bottom()))
+		}
+	}
+
+	for _, c := range base {
+		if err := add(c); err != nil {
+			return vxfw.Surface{}, err
+		}
+	}
+	for _, c := range l.constraints {
+		if err := add(c); err != nil {
+			return vxfw.Surface{}, err
+		}
+	}
+	solver.UpdateVariables()
+
+	size := vxfw.Size{
+		Width:  round(l.Width.Value(), math.MaxUint16-1),
+		Height: round(l.Height.Value(), math.MaxUint16-1),
+	}
+	surface := vxfw.Surface{
+		Size:     size,
+		Children: make([]vxfw.SubSurface, 0, len(l.children)),
+	}
+
+	for _, child := range l.children {
+		col := round(child.Left.Value(), size.Width)
+		row := round(child.Top.Value(), size.Height)
+		childSize := vxfw.Size{
+			Width:  round(child.Width.Value(), size.Width-col),
+			Height: round(child.Height.Value(), size.Height-row),
+		}
+
+		s, err := child.Widget.Draw(ctx.WithConstraints(childSize, childSize))
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+		surface.AddChild(int(col), int(row), s)
+	}
+
+	return surface, nil
+}
+
+// round rounds x to the nearest cell, clamped to [0, limit].
+func round(x float64, limit uint16) uint16 {
+	x = math.Round(x)
+	switch {
+	case x < 0:
+		return 0
+	case x > float64(limit):
+		return limit
+	default:
+		return uint16(x)
+	}
+}
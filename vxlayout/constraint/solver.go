@@ -0,0 +1,502 @@
+package constraint
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	// ErrUnsatisfiable is returned when a required constraint conflicts with other required
+	// constraints.
+	ErrUnsatisfiable = errors.New("constraint: unsatisfiable constraint")
+	// ErrDuplicate is returned when adding a constraint that is already in the solver.
+	ErrDuplicate = errors.New("constraint: duplicate constraint")
+	// ErrUnknown is returned when removing a constraint that is not in the solver.
+	ErrUnknown = errors.New("constraint: unknown constraint")
+	// ErrUnbounded is returned if the objective function is unbounded, which indicates a bug in
+	// the solver.
+	ErrUnbounded = errors.New("constraint: objective function is unbounded")
+)
+
+const epsilon = 1.0e-8
+
+func nearZero(x float64) bool {
+	return math.Abs(x) < epsilon
+}
+
+type symbolKind int
+
+const (
+	invalidSymbol symbolKind = iota
+	externalSymbol
+	slackSymbol
+	errorSymbol
+	dummySymbol
+)
+
+// symbol is a variable in the simplex tableau. The id is used to break ties so that solutions
+// don't depend on map iteration order.
+type symbol struct {
+	id   uint64
+	kind symbolKind
+}
+
+func (s symbol) valid() bool { return s.kind != invalidSymbol }
+
+// less reports whether s should be preferred over other when choosing between symbols.
+func (s symbol) less(other symbol) bool {
+	return !other.valid() || s.id < other.id
+}
+
+// row is a row in the simplex tableau: a constant plus a linear combination of symbols.
+type row struct {
+	constant float64
+	cells    map[symbol]float64
+}
+
+func newRow(constant float64) *row {
+	return &row{constant: constant, cells: make(map[symbol]float64)}
+}
+
+func (r *row) copy() *row {
+	out := newRow(r.constant)
+	for s, c := range r.cells {
+		out.cells[s] = c
+	}
+	return out
+}
+
+// insertSymbol adds coeff * s to the row, removing s if its coefficient becomes zero.
+func (r *row) insertSymbol(s symbol, coeff float64) {
+	c := r.cells[s] + coeff
+	if nearZero(c) {
+		delete(r.cells, s)
+	} else {
+		r.cells[s] = c
+	}
+}
+
+// insertRow adds coeff * other to the row.
+func (r *row) insertRow(other *row, coeff float64) {
+	r.constant += other.constant * coeff
+	for s, c := range other.cells {
+		r.insertSymbol(s, c*coeff)
+	}
+}
+
+func (r *row) reverseSign() {
+	r.constant = -r.constant
+	for s, c := range r.cells {
+		r.cells[s] = -c
+	}
+}
+
+// solveFor solves the row for s, assuming the row is equal to 0. s is removed from the row and
+// the remaining cells are the expression equal to s.
+func (r *row) solveFor(s symbol) {
+	coeff := -1.0 / r.cells[s]
+	delete(r.cells, s)
+	r.constant *= coeff
+	for sym, c := range r.cells {
+		r.cells[sym] = c * coeff
+	}
+}
+
+// solveForPair solves the row for rhs, assuming the row is equal to lhs.
+func (r *row) solveForPair(lhs, rhs symbol) {
+	r.insertSymbol(lhs, -1)
+	r.solveFor(rhs)
+}
+
+// substitute replaces s in the row with the expression in other.
+func (r *row) substitute(s symbol, other *row) {
+	if coeff, ok := r.cells[s]; ok {
+		delete(r.cells, s)
+		r.insertRow(other, coeff)
+	}
+}
+
+// tag tracks the symbols that were introduced for a constraint.
+type tag struct {
+	marker symbol
+	other  symbol
+}
+
+// Solver is an incremental Cassowary constraint solver, based on the design of the kiwi solver.
+// The zero value is not usable, use [NewSolver].
+type Solver struct {
+	constraints map[*Constraint]tag
+	rows        map[symbol]*row
+	vars        map[*Variable]symbol
+	objective   *row
+	artificial  *row
+	nextID      uint64
+}
+
+// NewSolver returns an empty [Solver].
+func NewSolver() *Solver {
+	return &Solver{
+		constraints: make(map[*Constraint]tag),
+		rows:        make(map[symbol]*row),
+		vars:        make(map[*Variable]symbol),
+		objective:   newRow(0),
+	}
+}
+
+func (s *Solver) newSymbol(kind symbolKind) symbol {
+	s.nextID++
+	return symbol{id: s.nextID, kind: kind}
+}
+
+// HasConstraint reports whether c has been added to the solver.
+func (s *Solver) HasConstraint(c *Constraint) bool {
+	_, ok := s.constraints[c]
+	return ok
+}
+
+// AddConstraint adds c to the solver. If c is required and cannot be satisfied,
+// [ErrUnsatisfiable] is returned and the solver is left unchanged.
+func (s *Solver) AddConstraint(c *Constraint) error {
+	if s.HasConstraint(c) {
+		return ErrDuplicate
+	}
+
+	var t tag
+	r := s.createRow(c, &t)
+	subject := chooseSubject(r, t)
+
+	// If the row only contains dummy variables the constraint is either redundant (the
+	// constant is 0) or unsatisfiable.
+	if !subject.valid() && allDummies(r) {
+		if !nearZero(r.constant) {
+			return ErrUnsatisfiable
+		}
+		subject = t.marker
+	}
+
+	if !subject.valid() {
+		// Adding with an artificial variable pivots the tableau, so keep a copy in case the
+		// constraint turns out to be unsatisfiable.
+		rows, objective := s.copyRows(), s.objective.copy()
+		ok, err := s.addWithArtificialVariable(r)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.rows, s.objective = rows, objective
+			return ErrUnsatisfiable
+		}
+	} else {
+		r.solveFor(subject)
+		s.substitute(subject, r)
+		s.rows[subject] = r
+	}
+
+	s.constraints[c] = t
+	return s.optimize(s.objective)
+}
+
+// RemoveConstraint removes c from the solver.
+func (s *Solver) RemoveConstraint(c *Constraint) error {
+	t, ok := s.constraints[c]
+	if !ok {
+		return ErrUnknown
+	}
+	delete(s.constraints, c)
+
+	// Remove the error effects from the objective before pivoting, or substitutions into the
+	// objective will lead to incorrect solver results.
+	s.removeMarkerEffects(t.marker, c.strength)
+	s.removeMarkerEffects(t.other, c.strength)
+
+	if _, ok := s.rows[t.marker]; ok {
+		delete(s.rows, t.marker)
+	} else {
+		leaving, r, ok := s.markerLeavingRow(t.marker)
+		if !ok {
+			return ErrUnknown
+		}
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, t.marker)
+		s.substitute(t.marker, r)
+	}
+
+	return s.optimize(s.objective)
+}
+
+// UpdateVariables writes the current solution into each [Variable] known to the solver.
+func (s *Solver) UpdateVariables() {
+	for v, sym := range s.vars {
+		if r, ok := s.rows[sym]; ok {
+			v.value = r.constant
+		} else {
+			v.value = 0
+		}
+	}
+}
+
+func (s *Solver) copyRows() map[symbol]*row {
+	out := make(map[symbol]*row, len(s.rows))
+	for sym, r := range s.rows {
+		out[sym] = r.copy()
+	}
+	return out
+}
+
+func (s *Solver) removeMarkerEffects(marker symbol, strength Strength) {
+	if marker.kind != errorSymbol {
+		return
+	}
+	if r, ok := s.rows[marker]; ok {
+		s.objective.insertRow(r, -float64(strength))
+	} else {
+		s.objective.insertSymbol(marker, -float64(strength))
+	}
+}
+
+// varSymbol returns the symbol for v, creating it if necessary.
+func (s *Solver) varSymbol(v *Variable) symbol {
+	if sym, ok := s.vars[v]; ok {
+		return sym
+	}
+	sym := s.newSymbol(externalSymbol)
+	s.vars[v] = sym
+	return sym
+}
+
+// createRow builds a tableau row for c, with any existing basic variables substituted out.
+// Slack, error and dummy symbols are added depending on the operator and strength of c.
+func (s *Solver) createRow(c *Constraint, t *tag) *row {
+	r := newRow(c.expr.Constant)
+	for _, term := range c.expr.Terms {
+		if nearZero(term.Coefficient) {
+			continue
+		}
+		sym := s.varSymbol(term.Variable)
+		if other, ok := s.rows[sym]; ok {
+			r.insertRow(other, term.Coefficient)
+		} else {
+			r.insertSymbol(sym, term.Coefficient)
+		}
+	}
+
+	strength := float64(c.strength)
+	switch c.op {
+	case OpLessEq, OpGreaterEq:
+		coeff := 1.0
+		if c.op == OpGreaterEq {
+			coeff = -1.0
+		}
+		slack := s.newSymbol(slackSymbol)
+		t.marker = slack
+		r.insertSymbol(slack, coeff)
+		if c.strength < Required {
+			errSym := s.newSymbol(errorSymbol)
+			t.other = errSym
+			r.insertSymbol(errSym, -coeff)
+			s.objective.insertSymbol(errSym, strength)
+		}
+	case OpEq:
+		if c.strength < Required {
+			plus := s.newSymbol(errorSymbol)
+			minus := s.newSymbol(errorSymbol)
+			t.marker = plus
+			t.other = minus
+			r.insertSymbol(plus, -1)
+			r.insertSymbol(minus, 1)
+			s.objective.insertSymbol(plus, strength)
+			s.objective.insertSymbol(minus, strength)
+		} else {
+			dummy := s.newSymbol(dummySymbol)
+			t.marker = dummy
+			r.insertSymbol(dummy, 1)
+		}
+	}
+
+	// The constant of a row must be non-negative
+	if r.constant < 0 {
+		r.reverseSign()
+	}
+	return r
+}
+
+// chooseSubject picks the symbol to solve a new row for. External symbols are preferred,
+// followed by the constraint's own slack or error symbols if they have a negative coefficient.
+func chooseSubject(r *row, t tag) symbol {
+	var subject symbol
+	for sym := range r.cells {
+		if sym.kind == externalSymbol && sym.less(subject) {
+			subject = sym
+		}
+	}
+	if subject.valid() {
+		return subject
+	}
+
+	for _, sym := range []symbol{t.marker, t.other} {
+		if sym.kind == slackSymbol || sym.kind == errorSymbol {
+			if r.cells[sym] < 0 {
+				return sym
+			}
+		}
+	}
+	return symbol{}
+}
+
+func allDummies(r *row) bool {
+	for sym := range r.cells {
+		if sym.kind != dummySymbol {
+			return false
+		}
+	}
+	return true
+}
+
+// addWithArtificialVariable adds r to the tableau using an artificial variable, and reports
+// whether the row could be satisfied.
+func (s *Solver) addWithArtificialVariable(r *row) (bool, error) {
+	art := s.newSymbol(slackSymbol)
+	s.rows[art] = r.copy()
+	s.artificial = r.copy()
+
+	// Minimize the artificial variable. If it can't reach 0, the row is unsatisfiable.
+	err := s.optimize(s.artificial)
+	success := nearZero(s.artificial.constant)
+	s.artificial = nil
+	if err != nil {
+		return false, err
+	}
+
+	// If the artificial variable is basic, pivot it out of the basis.
+	if ar, ok := s.rows[art]; ok {
+		delete(s.rows, art)
+		if len(ar.cells) == 0 {
+			return success, nil
+		}
+		entering := anyPivotableSymbol(ar)
+		if !entering.valid() {
+			return false, nil
+		}
+		ar.solveForPair(art, entering)
+		s.substitute(entering, ar)
+		s.rows[entering] = ar
+	}
+
+	// The artificial variable is now non-basic and can be removed.
+	for _, other := range s.rows {
+		delete(other.cells, art)
+	}
+	delete(s.objective.cells, art)
+	return success, nil
+}
+
+func anyPivotableSymbol(r *row) symbol {
+	var out symbol
+	for sym := range r.cells {
+		if (sym.kind == slackSymbol || sym.kind == errorSymbol) && sym.less(out) {
+			out = sym
+		}
+	}
+	return out
+}
+
+// substitute replaces sym in every row of the tableau with r.
+func (s *Solver) substitute(sym symbol, r *row) {
+	for _, other := range s.rows {
+		other.substitute(sym, r)
+	}
+	s.objective.substitute(sym, r)
+	if s.artificial != nil {
+		s.artificial.substitute(sym, r)
+	}
+}
+
+// optimize runs the primal simplex method on objective until it can't be decreased any further.
+func (s *Solver) optimize(objective *row) error {
+	for {
+		entering := enteringSymbol(objective)
+		if !entering.valid() {
+			return nil
+		}
+
+		leaving, r, ok := s.leavingRow(entering)
+		if !ok {
+			return ErrUnbounded
+		}
+
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, entering)
+		s.substitute(entering, r)
+		s.rows[entering] = r
+	}
+}
+
+// enteringSymbol returns a non-dummy symbol with a negative coefficient in objective.
+func enteringSymbol(objective *row) symbol {
+	var out symbol
+	for sym, coeff := range objective.cells {
+		if sym.kind != dummySymbol && coeff < 0 && sym.less(out) {
+			out = sym
+		}
+	}
+	return out
+}
+
+// leavingRow finds the row with the minimum ratio for the entering symbol.
+func (s *Solver) leavingRow(entering symbol) (symbol, *row, bool) {
+	ratio := math.MaxFloat64
+	var found symbol
+	for sym, r := range s.rows {
+		if sym.kind == externalSymbol {
+			continue
+		}
+		coeff := r.cells[entering]
+		if coeff >= 0 {
+			continue
+		}
+		rr := -r.constant / coeff
+		if rr < ratio || (rr == ratio && sym.less(found)) {
+			ratio = rr
+			found = sym
+		}
+	}
+	if !found.valid() {
+		return symbol{}, nil, false
+	}
+	return found, s.rows[found], true
+}
+
+// markerLeavingRow finds the row to pivot out when removing the constraint that owns marker.
+func (s *Solver) markerLeavingRow(marker symbol) (symbol, *row, bool) {
+	r1, r2 := math.MaxFloat64, math.MaxFloat64
+	var first, second, third symbol
+	for sym, r := range s.rows {
+		coeff, ok := r.cells[marker]
+		if !ok {
+			continue
+		}
+		switch {
+		case sym.kind == externalSymbol:
+			if sym.less(third) {
+				third = sym
+			}
+		case coeff < 0:
+			if rr := -r.constant / coeff; rr < r1 || (rr == r1 && sym.less(first)) {
+				r1 = rr
+				first = sym
+			}
+		default:
+			if rr := r.constant / coeff; rr < r2 || (rr == r2 && sym.less(second)) {
+				r2 = rr
+				second = sym
+			}
+		}
+	}
+
+	for _, sym := range []symbol{first, second, third} {
+		if sym.valid() {
+			return sym, s.rows[sym], true
+		}
+	}
+	return symbol{}, nil, false
+}
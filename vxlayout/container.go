@@ -0,0 +1,261 @@
+package vxlayout
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/theme"
+)
+
+// Insets are space on each side of a box.
+type Insets struct {
+	Top, Right, Bottom, Left uint16
+}
+
+// All returns [Insets] of n on every side.
+func All(n uint16) Insets {
+	return Insets{Top: n, Right: n, Bottom: n, Left: n}
+}
+
+// Symmetric returns [Insets] of vertical above and below, and horizontal on the left and right.
+func Symmetric(vertical, horizontal uint16) Insets {
+	return Insets{Top: vertical, Right: horizontal, Bottom: vertical, Left: horizontal}
+}
+
+// size returns the total size of the insets on each axis.
+func (in Insets) size() vxfw.Size {
+	return vxfw.Size{Width: in.Left + in.Right, Height: in.Top + in.Bottom}
+}
+
+// Border is the set of graphemes a border is drawn with. The zero value draws no border.
+type Border struct {
+	TopLeft, Top, TopRight          string
+	Left, Right                     string
+	BottomLeft, Bottom, BottomRight string
+}
+
+var (
+	NoBorder      = Border{}
+	LineBorder    = Border{"┌", "─", "┐", "│", "│", "└", "─", "┘"}
+	RoundedBorder = Border{"╭", "─", "╮", "│", "│", "╰", "─", "╯"}
+	DoubleBorder  = Border{"╔", "═", "╗", "║", "║", "╚", "═", "╝"}
+	ThickBorder   = Border{"┏", "━", "┓", "┃", "┃", "┗", "━", "┛"}
+	ASCIIBorder   = Border{"+", "-", "+", "|", "|", "+", "-", "+"}
+)
+
+// width returns the width of the border on each side, which is 1 unless b is [NoBorder].
+func (b Border) width() uint16 {
+	if b == NoBorder {
+		return 0
+	}
+	return 1
+}
+
+// Alignment positions a child within a larger space on one axis.
+type Alignment int
+
+const (
+	AlignStart Alignment = iota
+	AlignCenter
+	AlignEnd
+	// AlignStretch makes the child fill the space, if the space is bounded.
+	AlignStretch
+)
+
+// offset returns the offset of a child of size within space.
+func (a Alignment) offset(space, size uint16) uint16 {
+	if size >= space {
+		return 0
+	}
+	switch a {
+	case AlignCenter:
+		return (space - size) / 2
+	case AlignEnd:
+		return space - size
+	default:
+		return 0
+	}
+}
+
+// Container is a [vxfw.Widget] which decorates and positions its child. From the outside in, a
+// container has a transparent margin, a border, and padding around the child. The box inside the
+// margin is filled with the background style, including the padding and any space the child
+// doesn't cover.
+//
+// The box takes the size of the child plus its padding and border, within Min and Max and the
+// incoming constraints. When the box is larger than that, for example inside an [Expanded], the
+// child is placed in the remaining space according to AlignX and AlignY.
+//
+// The background and border are styled with roles of the theme provided by the container's
+// ancestors (see [theme.From]), unless the roles are empty.
+type Container struct {
+	Child vxfw.Widget
+
+	// Role styles the background. If Role is empty, Style is used instead.
+	Role  theme.Role
+	Style vaxis.Style
+
+	Border Border
+	// BorderRole styles the border. If BorderRole is empty, BorderStyle is used instead.
+	BorderRole  theme.Role
+	BorderStyle vaxis.Style
+
+	Padding Insets
+	Margin  Insets
+
+	// Min and Max constrain the size of the box, not including the margin. An axis of 0 is
+	// ignored.
+	Min vxfw.Size
+	Max vxfw.Size
+
+	AlignX Alignment
+	AlignY Alignment
+}
+
+var _ vxfw.Widget = &Container{}
+
+func (c *Container) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	margin := c.Margin.size()
+	b := c.Border.width()
+	chrome := c.Padding.size()
+	chrome.Width += 2 * b
+	chrome.Height += 2 * b
+
+	// The constraints of the box, inside the margin
+	boxMin := vxfw.Size{Width: sub(ctx.Min.Width, margin.Width), Height: sub(ctx.Min.Height, margin.Height)}
+	boxMax := inset(ctx.Max, margin)
+	if c.Max.Width > 0 && c.Max.Width < boxMax.Width {
+		boxMax.Width = c.Max.Width
+	}
+	if c.Max.Height > 0 && c.Max.Height < boxMax.Height {
+		boxMax.Height = c.Max.Height
+	}
+	if c.Min.Width > boxMin.Width {
+		boxMin.Width = c.Min.Width
+	}
+	if c.Min.Height > boxMin.Height {
+		boxMin.Height = c.Min.Height
+	}
+	if boxMin.Width > boxMax.Width {
+		boxMin.Width = boxMax.Width
+	}
+	if boxMin.Height > boxMax.Height {
+		boxMin.Height = boxMax.Height
+	}
+
+	// The child is measured loosely, or stretched to fill the box on either axis
+	childMax := inset(boxMax, chrome)
+	var childMin vxfw.Size
+	if c.AlignX == AlignStretch && !childMax.HasUnboundedWidth() {
+		childMin.Width = childMax.Width
+	}
+	if c.AlignY == AlignStretch && !childMax.HasUnboundedHeight() {
+		childMin.Height = childMax.Height
+	}
+
+	var child vxfw.Surface
+	if c.Child != nil {
+		var err error
+		child, err = c.Child.Draw(ctx.WithConstraints(childMin, childMax))
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+	}
+
+	box := vxfw.Size{Width: child.Size.Width + chrome.Width, Height: child.Size.Height + chrome.Height}
+	if box.Width < boxMin.Width {
+		box.Width = boxMin.Width
+	}
+	if box.Height < boxMin.Height {
+		box.Height = boxMin.Height
+	}
+	if box.Width > boxMax.Width {
+		box.Width = boxMax.Width
+	}
+	if box.Height > boxMax.Height {
+		box.Height = boxMax.Height
+	}
+
+	th := theme.From(ctx)
+	style := c.Style
+	if c.Role != "" {
+		style = th.Style(c.Role)
+	}
+	borderStyle := c.BorderStyle
+	if c.BorderRole != "" {
+		borderStyle = th.Style(c.BorderRole)
+	}
+
+	surface := vxfw.NewSurface(box.Width, box.Height, c)
+	surface.Fill(vaxis.Cell{Character: vaxis.Character{Grapheme: " ", Width: 1}, Style: style})
+	if b > 0 {
+		drawBorder(ctx, &surface, c.Border, borderStyle)
+	}
+
+	content := vxfw.Size{Width: sub(box.Width, chrome.Width), Height: sub(box.Height, chrome.Height)}
+	col := b + c.Padding.Left + c.AlignX.offset(content.Width, child.Size.Width)
+	row := b + c.Padding.Top + c.AlignY.offset(content.Height, child.Size.Height)
+	if c.Child != nil {
+		surface.AddChild(int(col), int(row), child)
+	}
+
+	if margin == (vxfw.Size{}) {
+		return surface, nil
+	}
+
+	// The margin is transparent, so the outer surface has no cells of its own
+	outer := vxfw.Surface{Size: vxfw.Size{Width: box.Width + margin.Width, Height: box.Height + margin.Height}}
+	outer.Widget = c
+	outer.AddChild(int(c.Margin.Left), int(c.Margin.Top), surface)
+	return outer, nil
+}
+
+// drawBorder draws border around the edge of s.
+func drawBorder(ctx vxfw.DrawContext, s *vxfw.Surface, border Border, style vaxis.Style) {
+	w, h := s.Size.Width, s.Size.Height
+	if w == 0 || h == 0 {
+		return
+	}
+
+	cell := func(grapheme string) vaxis.Cell {
+		out := vaxis.Cell{Style: style}
+		if chars := ctx.Characters(grapheme); len(chars) > 0 {
+			out.Character = chars[0]
+		}
+		return out
+	}
+
+	top, bottom := cell(border.Top), cell(border.Bottom)
+	for col := uint16(1); col+1 < w; col++ {
+		s.WriteCell(col, 0, top)
+		s.WriteCell(col, h-1, bottom)
+	}
+	left, right := cell(border.Left), cell(border.Right)
+	for row := uint16(1); row+1 < h; row++ {
+		s.WriteCell(0, row, left)
+		s.WriteCell(w-1, row, right)
+	}
+	s.WriteCell(0, 0, cell(border.TopLeft))
+	s.WriteCell(w-1, 0, cell(border.TopRight))
+	s.WriteCell(0, h-1, cell(border.BottomLeft))
+	s.WriteCell(w-1, h-1, cell(border.BottomRight))
+}
+
+// inset returns size less by on each axis. Unbounded axes stay unbounded.
+func inset(size, by vxfw.Size) vxfw.Size {
+	out := vxfw.Size{Width: sub(size.Width, by.Width), Height: sub(size.Height, by.Height)}
+	if size.HasUnboundedWidth() {
+		out.Width = size.Width
+	}
+	if size.HasUnboundedHeight() {
+		out.Height = size.Height
+	}
+	return out
+}
+
+// sub returns a - b, or 0 if b is larger than a.
+func sub(a, b uint16) uint16 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
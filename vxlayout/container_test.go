@@ -0,0 +1,111 @@
+package vxlayout
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp"
+	"github.com/avidal/vxexp/theme"
+)
+
+func TestContainer(t *testing.T) {
+	tests := []struct {
+		name      string
+		container *Container
+		max       vxfw.Size
+		want      []string
+	}{
+		{
+			name:      "padding and border",
+			container: &Container{Child: text.New("hi"), Border: LineBorder, Padding: Symmetric(0, 1)},
+			max:       vxfw.Size{Width: 20, Height: 20},
+			want:      []string{"┌────┐", "│ hi │", "└────┘"},
+		},
+		{
+			name:      "margin",
+			container: &Container{Child: text.New("hi"), Border: ASCIIBorder, Margin: Insets{Top: 1, Left: 2}},
+			max:       vxfw.Size{Width: 20, Height: 20},
+			want:      []string{"______", "__+--+", "__|hi|", "__+--+"},
+		},
+		{
+			name: "min and alignment",
+			container: &Container{
+				Child:  text.New("x"),
+				Min:    vxfw.Size{Width: 5, Height: 3},
+				AlignX: AlignCenter,
+				AlignY: AlignEnd,
+			},
+			max:  vxfw.Size{Width: 20, Height: 20},
+			want: []string{"     ", "     ", "  x  "},
+		},
+		{
+			name:      "max",
+			container: &Container{Child: text.New("abcdef"), Border: ASCIIBorder, Max: vxfw.Size{Width: 5}},
+			max:       vxfw.Size{Width: 20, Height: 20},
+			want:      []string{"+---+", "|abc|", "|def|", "+---+"},
+		},
+		{
+			name:      "stretch",
+			container: &Container{Child: TileText(vaxis.Style{}, "·"), AlignX: AlignStretch, AlignY: AlignStretch, Padding: All(1)},
+			max:       vxfw.Size{Width: 4, Height: 3},
+			want:      []string{"    ", " ·· ", "    "},
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := vxfw.DrawContext{Max: tt.max, Characters: vaxis.Characters}
+		s, err := tt.container.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := graphemes(vxexp.Flatten(s))
+		if len(got) != len(tt.want) {
+			t.Logf("wrong %s rows, got=%q, want=%q", tt.name, got, tt.want)
+			t.Fail()
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Logf("wrong %s row %d, got=%q, want=%q", tt.name, i, got[i], tt.want[i])
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestContainerStyle(t *testing.T) {
+	navy := vaxis.Style{Background: vaxis.ColorNavy}
+	border := vaxis.Style{Foreground: vaxis.ColorGray}
+	th := theme.Default.Extend(map[theme.Role]vaxis.Style{theme.Border: border})
+
+	c := &Container{
+		Child:      text.New("a"),
+		Style:      navy,
+		Border:     LineBorder,
+		BorderRole: theme.Border,
+		Padding:    All(1),
+	}
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 20, Height: 20}, Characters: vaxis.Characters}
+	s, err := theme.Provide(th, c).Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The whole box is styled, including the padding around the child
+	box := s.Children[0].Surface
+	if box.Size != (vxfw.Size{Width: 5, Height: 5}) {
+		t.Fatalf("wrong box size, got=%v", box.Size)
+	}
+	if got := box.Buffer[0].Style; got != border {
+		t.Logf("wrong border style, got=%v, want=%v", got, border)
+		t.Fail()
+	}
+	for _, i := range []int{6, 7, 8, 11, 13, 16, 17, 18} {
+		if got := box.Buffer[i].Style; got != navy {
+			t.Logf("wrong background style at %d, got=%v, want=%v", i, got, navy)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package vxlayout
+
+import (
+	"math"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/theme"
+)
+
+// Divider is a [vxfw.Widget] that draws a line one cell thick, with an optional label in the
+// middle. A horizontal divider is drawn with the Top grapheme of Line, and a vertical divider with
+// the Left grapheme.
+//
+// A divider is as long as the incoming constraints allow. When its length is unbounded, as it is
+// along the main axis of a [Row] or [Column], it's only long enough for its label. A [Row] or
+// [Column] draws dividers which cross its main axis, like a [VDivider] in a [Row], after its other
+// children, so they span the height of the row or the width of the column.
+type Divider struct {
+	Orientation Orientation
+	// Line is the set of graphemes the line is drawn with. The zero value is [LineBorder].
+	Line  Border
+	Label string
+
+	// Role styles the line and label. If Role is empty, Style is used instead.
+	Role  theme.Role
+	Style vaxis.Style
+}
+
+// HDivider returns a horizontal [Divider] with label, styled with [theme.Border].
+func HDivider(label string) *Divider {
+	return &Divider{Orientation: Horizontal, Line: LineBorder, Label: label, Role: theme.Border}
+}
+
+// VDivider returns a vertical [Divider] with label, styled with [theme.Border].
+func VDivider(label string) *Divider {
+	return &Divider{Orientation: Vertical, Line: LineBorder, Label: label, Role: theme.Border}
+}
+
+var _ vxfw.Widget = &Divider{}
+
+func (d *Divider) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	line := d.Line
+	if line == NoBorder {
+		line = LineBorder
+	}
+	grapheme := line.Top
+	if d.Orientation == Vertical {
+		grapheme = line.Left
+	}
+
+	style := d.Style
+	if d.Role != "" {
+		style = theme.From(ctx).Style(d.Role)
+	}
+
+	lineCell := vaxis.Cell{Style: style}
+	if chars := ctx.Characters(grapheme); len(chars) > 0 {
+		lineCell.Character = chars[0]
+	}
+	var label []vaxis.Character
+	if d.Label != "" {
+		label = ctx.Characters(d.Label)
+	}
+
+	// The length the label takes along the line, with a space on either side
+	var labelLength uint16
+	if len(label) > 0 {
+		labelLength = 2
+		for _, char := range label {
+			if d.Orientation == Horizontal {
+				labelLength += uint16(char.Width)
+			} else {
+				labelLength++
+			}
+		}
+	}
+
+	length := d.Orientation.MainAxis(ctx.Max)
+	if length == math.MaxUint16 {
+		length = labelLength
+		if min := d.Orientation.MainAxis(ctx.Min); min > length {
+			length = min
+		}
+		if length == 0 {
+			length = 1
+		}
+	}
+
+	size := d.Orientation.Size(length, 1)
+	surface := vxfw.NewSurface(size.Width, size.Height, d)
+	for i := uint16(0); i < length; i++ {
+		d.write(&surface, i, lineCell)
+	}
+
+	if len(label) == 0 || labelLength > length {
+		return surface, nil
+	}
+
+	pos := (length - labelLength) / 2
+	blank := vaxis.Cell{Character: vaxis.Character{Grapheme: " ", Width: 1}, Style: style}
+	d.write(&surface, pos, blank)
+	pos++
+	for _, char := range label {
+		d.write(&surface, pos, vaxis.Cell{Character: char, Style: style})
+		if d.Orientation == Horizontal {
+			pos += uint16(char.Width)
+		} else {
+			pos++
+		}
+	}
+	d.write(&surface, pos, blank)
+	return surface, nil
+}
+
+// write writes cell at pos along the line of s.
+func (d *Divider) write(s *vxfw.Surface, pos uint16, cell vaxis.Cell) {
+	if d.Orientation == Horizontal {
+		s.WriteCell(pos, 0, cell)
+	} else {
+		s.WriteCell(0, pos, cell)
+	}
+}
@@ -0,0 +1,122 @@
+package vxlayout
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp"
+)
+
+func TestDivider(t *testing.T) {
+	tests := []struct {
+		name    string
+		divider *Divider
+		max     vxfw.Size
+		want    []string
+	}{
+		{
+			name:    "horizontal",
+			divider: HDivider(""),
+			max:     vxfw.Size{Width: 5, Height: 5},
+			want:    []string{"─────"},
+		},
+		{
+			name:    "label",
+			divider: HDivider("ab"),
+			max:     vxfw.Size{Width: 10, Height: 5},
+			want:    []string{"─── ab ───"},
+		},
+		{
+			name:    "label too long",
+			divider: HDivider("abcdef"),
+			max:     vxfw.Size{Width: 6, Height: 5},
+			want:    []string{"──────"},
+		},
+		{
+			name:    "unbounded",
+			divider: &Divider{Line: DoubleBorder, Label: "ab"},
+			max:     vxfw.Size{Width: 0xFFFF, Height: 5},
+			want:    []string{" ab "},
+		},
+		{
+			name:    "vertical",
+			divider: &Divider{Orientation: Vertical, Line: ASCIIBorder, Label: "a"},
+			max:     vxfw.Size{Width: 5, Height: 5},
+			want:    []string{"|", " ", "a", " ", "|"},
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := vxfw.DrawContext{Max: tt.max, Characters: vaxis.Characters}
+		s, err := tt.divider.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := graphemes(s)
+		if len(got) != len(tt.want) {
+			t.Logf("wrong %s rows, got=%q, want=%q", tt.name, got, tt.want)
+			t.Fail()
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Logf("wrong %s row %d, got=%q, want=%q", tt.name, i, got[i], tt.want[i])
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestFlexSeparator(t *testing.T) {
+	row := Row([]vxfw.Widget{
+		text.New("a\nb"),
+		text.New("c"),
+		Expanded(text.New("d"), 1),
+	}, Options{Separator: &Divider{Orientation: Vertical, Line: ASCIIBorder}, CrossAxis: CrossAxisStart})
+	column := Column([]vxfw.Widget{
+		text.New("ab"),
+		text.New("c"),
+	}, Options{Separator: &Divider{Line: ASCIIBorder}, Gap: 1, CrossAxis: CrossAxisStart})
+
+	tests := []struct {
+		name   string
+		layout vxfw.Widget
+		max    vxfw.Size
+		want   []string
+	}{
+		{
+			name:   "row",
+			layout: row,
+			max:    vxfw.Size{Width: 7, Height: 10},
+			want:   []string{"a|c|d__", "b|_|___"},
+		},
+		{
+			name:   "column",
+			layout: column,
+			max:    vxfw.Size{Width: 10, Height: 5},
+			want:   []string{"ab", "__", "--", "__", "c_"},
+		},
+	}
+
+	for _, tt := range tests {
+		ctx := vxfw.DrawContext{Max: tt.max, Characters: vaxis.Characters}
+		s, err := tt.layout.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := graphemes(vxexp.Flatten(s))
+		if len(got) != len(tt.want) {
+			t.Logf("wrong %s rows, got=%q, want=%q", tt.name, got, tt.want)
+			t.Fail()
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Logf("wrong %s row %d, got=%q, want=%q", tt.name, i, got[i], tt.want[i])
+				t.Fail()
+			}
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package vxlayout
+
+import (
+	"math"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp"
+	"github.com/avidal/vxexp/theme"
+)
+
+// Fill returns a [vxfw.Widget] that fills its space with the supplied cell. Wide graphemes are
+// placed every cell.Width columns.
+// Note that Fill, like the other fills below, will take all available space. It's primarily useful
+// to diagnose layouts and draw backgrounds, and will usually be contained in a [Flexible]
+func Fill(cell vaxis.Cell) vxfw.Widget {
+	return Tile([]vaxis.Cell{cell})
+}
+
+// FillRole returns a [vxfw.Widget] that fills its space with grapheme, styled with role in the
+// theme provided by its ancestors. See [theme.From].
+func FillRole(grapheme string, role theme.Role) vxfw.Widget {
+	return vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		cell := vaxis.Cell{Style: theme.From(ctx).Style(role)}
+		if chars := ctx.Characters(grapheme); len(chars) > 0 {
+			cell.Character = chars[0]
+		}
+		return Fill(cell).Draw(ctx)
+	})
+}
+
+// Tile returns a [vxfw.Widget] that fills its space by repeating a pattern, given as rows of
+// cells, across and down. The pattern is as wide as its widest row, and shorter rows are padded
+// with blank cells. A wide grapheme which would cross the edge of the space is replaced by blank
+// cells of the same style.
+func Tile(pattern ...[]vaxis.Cell) vxfw.Widget {
+	return vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		return drawTile(ctx, pattern), nil
+	})
+}
+
+// TileText is like [Tile], with a pattern made of lines of text in style.
+func TileText(style vaxis.Style, lines ...string) vxfw.Widget {
+	return vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		pattern := make([][]vaxis.Cell, len(lines))
+		for i, line := range lines {
+			for _, char := range ctx.Characters(line) {
+				pattern[i] = append(pattern[i], vaxis.Cell{Character: char, Style: style})
+			}
+		}
+		return drawTile(ctx, pattern), nil
+	})
+}
+
+// Checkerboard returns a [vxfw.Widget] that fills its space with alternating squares of a and b,
+// each size large. A size of 0 on either axis is treated as 1.
+func Checkerboard(a, b vaxis.Cell, size vxfw.Size) vxfw.Widget {
+	if size.Width == 0 {
+		size.Width = 1
+	}
+	if size.Height == 0 {
+		size.Height = 1
+	}
+
+	pattern := make([][]vaxis.Cell, 2*size.Height)
+	for row := range pattern {
+		first, second := a, b
+		if row >= int(size.Height) {
+			first, second = b, a
+		}
+		for i := 0; i < int(size.Width); i++ {
+			pattern[row] = append(pattern[row], first)
+		}
+		for i := 0; i < int(size.Width); i++ {
+			pattern[row] = append(pattern[row], second)
+		}
+	}
+	return Tile(pattern...)
+}
+
+// tileCell is a cell of a tile row. Columns covered by the right side of a wide grapheme have no
+// cell of their own, and refer to the cell which covers them instead.
+type tileCell struct {
+	cell  vaxis.Cell
+	width int
+	// start is false for columns covered by the grapheme which starts in an earlier column
+	start bool
+}
+
+func drawTile(ctx vxfw.DrawContext, pattern [][]vaxis.Cell) vxfw.Surface {
+	surface := vxfw.NewSurface(ctx.Max.Width, ctx.Max.Height, nil)
+
+	// Lay out each row of the pattern by column
+	var rows [][]tileCell
+	width := 0
+	for _, cells := range pattern {
+		var row []tileCell
+		for _, cell := range cells {
+			w := cell.Width
+			if w < 1 {
+				w = 1
+			}
+			row = append(row, tileCell{cell: cell, width: w, start: true})
+			for i := 1; i < w; i++ {
+				row = append(row, tileCell{cell: cell, width: w})
+			}
+		}
+		if len(row) > width {
+			width = len(row)
+		}
+		rows = append(rows, row)
+	}
+	if width == 0 || len(rows) == 0 {
+		return surface
+	}
+
+	blank := func(style vaxis.Style) vaxis.Cell {
+		return vaxis.Cell{Character: vaxis.Character{Grapheme: " ", Width: 1}, Style: style}
+	}
+
+	for y := 0; y < int(surface.Size.Height); y++ {
+		row := rows[y%len(rows)]
+		for x := 0; x < int(surface.Size.Width); {
+			offset := x % width
+			if offset >= len(row) {
+				surface.WriteCell(uint16(x), uint16(y), blank(vaxis.Style{}))
+				x++
+				continue
+			}
+
+			tc := row[offset]
+			if !tc.start || x+tc.width > int(surface.Size.Width) {
+				surface.WriteCell(uint16(x), uint16(y), blank(tc.cell.Style))
+				x++
+				continue
+			}
+
+			surface.WriteCell(uint16(x), uint16(y), tc.cell)
+			// The columns under the rest of a wide grapheme keep its style
+			for i := 1; i < tc.width; i++ {
+				surface.WriteCell(uint16(x+i), uint16(y), vaxis.Cell{Style: tc.cell.Style})
+			}
+			x += tc.width
+		}
+	}
+	return surface
+}
+
+// ColorSpace is the color space a [Gradient] is interpolated in.
+type ColorSpace int
+
+const (
+	// RGB interpolates each of the red, green and blue channels of sRGB colors.
+	RGB ColorSpace = iota
+	// OKLab interpolates in the OKLab color space, which gives perceptually even steps and
+	// avoids the muddy midpoints of RGB gradients.
+	OKLab
+)
+
+// Gradient returns a [vxfw.Widget] that fills its space with blank cells whose background color
+// goes through stops, evenly spaced along axis.
+// Indexed colors are converted to the colors of the standard xterm palette, which may not be the
+// colors the terminal shows for the first 16. The default color can't be interpolated: a step
+// between the default color and another color takes the closest of the two.
+func Gradient(axis Orientation, space ColorSpace, stops ...vaxis.Color) vxfw.Widget {
+	return vxexp.WidgetFunc(func(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+		surface := vxfw.NewSurface(ctx.Max.Width, ctx.Max.Height, nil)
+		if len(stops) == 0 {
+			return surface, nil
+		}
+
+		length := int(axis.MainAxis(surface.Size))
+		for i := 0; i < length; i++ {
+			t := 0.0
+			if length > 1 {
+				t = float64(i) / float64(length-1)
+			}
+			cell := vaxis.Cell{
+				Character: vaxis.Character{Grapheme: " ", Width: 1},
+				Style:     vaxis.Style{Background: gradientAt(stops, t, space)},
+			}
+
+			if axis == Horizontal {
+				for row := uint16(0); row < surface.Size.Height; row++ {
+					surface.WriteCell(uint16(i), row, cell)
+				}
+			} else {
+				for col := uint16(0); col < surface.Size.Width; col++ {
+					surface.WriteCell(col, uint16(i), cell)
+				}
+			}
+		}
+		return surface, nil
+	})
+}
+
+// gradientAt returns the color at t, from 0 to 1, along a gradient through stops.
+func gradientAt(stops []vaxis.Color, t float64, space ColorSpace) vaxis.Color {
+	if len(stops) == 1 {
+		return stops[0]
+	}
+	pos := t * float64(len(stops)-1)
+	i := int(pos)
+	if i >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+	return interpolate(stops[i], stops[i+1], pos-float64(i), space)
+}
+
+// interpolate returns the color a fraction t of the way from a to b.
+func interpolate(a, b vaxis.Color, t float64, space ColorSpace) vaxis.Color {
+	ra, ok := toRGB(a)
+	rb, okb := toRGB(b)
+	if !ok || !okb {
+		if t < 0.5 {
+			return a
+		}
+		return b
+	}
+
+	var out [3]float64
+	switch space {
+	case OKLab:
+		la, lb := linearToOKLab(srgbToLinear(ra)), linearToOKLab(srgbToLinear(rb))
+		var lab [3]float64
+		for i := range lab {
+			lab[i] = la[i] + (lb[i]-la[i])*t
+		}
+		out = linearToSRGB(okLabToLinear(lab))
+	default:
+		for i := range out {
+			out[i] = ra[i] + (rb[i]-ra[i])*t
+		}
+	}
+
+	var channels [3]uint8
+	for i, v := range out {
+		channels[i] = uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	return vaxis.RGBColor(channels[0], channels[1], channels[2])
+}
+
+// ansiColors are the first 16 colors of the xterm palette.
+var ansiColors = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// toRGB returns the sRGB channels of c, from 0 to 1, or false for the default color.
+func toRGB(c vaxis.Color) ([3]float64, bool) {
+	var rgb [3]uint8
+	switch params := c.Params(); len(params) {
+	case 3:
+		copy(rgb[:], params)
+	case 1:
+		switch i := int(params[0]); {
+		case i < 16:
+			rgb = ansiColors[i]
+		case i < 232:
+			// The 6x6x6 color cube
+			levels := [6]uint8{0, 95, 135, 175, 215, 255}
+			i -= 16
+			rgb = [3]uint8{levels[i/36], levels[i/6%6], levels[i%6]}
+		default:
+			// The grayscale ramp
+			v := uint8(8 + (i-232)*10)
+			rgb = [3]uint8{v, v, v}
+		}
+	default:
+		return [3]float64{}, false
+	}
+	return [3]float64{float64(rgb[0]) / 255, float64(rgb[1]) / 255, float64(rgb[2]) / 255}, true
+}
+
+func srgbToLinear(c [3]float64) [3]float64 {
+	for i, v := range c {
+		if v <= 0.04045 {
+			c[i] = v / 12.92
+		} else {
+			c[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+	return c
+}
+
+func linearToSRGB(c [3]float64) [3]float64 {
+	for i, v := range c {
+		if v <= 0.0031308 {
+			c[i] = v * 12.92
+		} else {
+			c[i] = 1.055*math.Pow(v, 1/2.4) - 0.055
+		}
+	}
+	return c
+}
+
+// linearToOKLab and okLabToLinear convert between linear sRGB and OKLab, see
+// https://bottosson.github.io/posts/oklab/
+func linearToOKLab(c [3]float64) [3]float64 {
+	l := math.Cbrt(0.4122214708*c[0] + 0.5363325363*c[1] + 0.0514459929*c[2])
+	m := math.Cbrt(0.2119034982*c[0] + 0.6806995451*c[1] + 0.1073969566*c[2])
+	s := math.Cbrt(0.0883024619*c[0] + 0.2817188376*c[1] + 0.6299787005*c[2])
+	return [3]float64{
+		0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+func okLabToLinear(lab [3]float64) [3]float64 {
+	l := lab[0] + 0.3963377774*lab[1] + 0.2158037573*lab[2]
+	m := lab[0] - 0.1055613458*lab[1] - 0.0638541728*lab[2]
+	s := lab[0] - 0.0894841775*lab[1] - 1.2914855480*lab[2]
+	l, m, s = l*l*l, m*m*m, s*s*s
+	return [3]float64{
+		4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960771*l - 0.7034186147*m + 1.7076147010*s,
+	}
+}
@@ -0,0 +1,117 @@
+package vxlayout
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/theme"
+)
+
+// graphemes returns the graphemes of each row of s, with "_" for cells without a grapheme.
+func graphemes(s vxfw.Surface) []string {
+	out := make([]string, s.Size.Height)
+	for i, cell := range s.Buffer {
+		g := cell.Grapheme
+		if g == "" {
+			g = "_"
+		}
+		out[i/int(s.Size.Width)] += g
+	}
+	return out
+}
+
+func TestFillRole(t *testing.T) {
+	style := vaxis.Style{Background: vaxis.ColorNavy}
+	th := theme.Default.Extend(map[theme.Role]vaxis.Style{theme.Muted: style})
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 3, Height: 2}, Characters: vaxis.Characters}
+	surface, err := theme.Provide(th, FillRole("·", theme.Muted)).Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := surface.Children[0].Surface
+	for i, cell := range fill.Buffer {
+		if cell.Grapheme != "·" || cell.Style != style {
+			t.Logf("wrong cell %d, got=%+v", i, cell)
+			t.Fail()
+		}
+	}
+}
+
+func TestTile(t *testing.T) {
+	wide := vaxis.Cell{Character: vaxis.Character{Grapheme: "語", Width: 2}}
+	x := vaxis.Cell{Character: vaxis.Character{Grapheme: "x", Width: 1}}
+	o := vaxis.Cell{Character: vaxis.Character{Grapheme: "o", Width: 1}}
+
+	tests := []struct {
+		name   string
+		widget vxfw.Widget
+		want   []string
+	}{
+		// Wide graphemes are placed every other column, and blanked at the edge
+		{"fill", Fill(wide), []string{"語_語_ ", "語_語_ "}},
+		{"tile", Tile([]vaxis.Cell{x, wide}, []vaxis.Cell{o}), []string{"x語_x語_", "o  o  "}},
+		{"text", TileText(vaxis.Style{}, "ab", "c"), []string{"ababa", "c c c"}},
+		{"checkerboard", Checkerboard(x, o, vxfw.Size{Width: 2}), []string{"xxooxx", "ooxxoo"}},
+	}
+	for _, tt := range tests {
+		width := uint16(5)
+		if tt.name != "fill" && tt.name != "text" {
+			width = 6
+		}
+		ctx := vxfw.DrawContext{Max: vxfw.Size{Width: width, Height: 2}, Characters: vaxis.Characters}
+		s, err := tt.widget.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := graphemes(s)
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Logf("wrong %s row %d, got=%q, want=%q", tt.name, i, got[i], tt.want[i])
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	black, white := vaxis.RGBColor(0, 0, 0), vaxis.RGBColor(255, 255, 255)
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 3, Height: 2}, Characters: vaxis.Characters}
+
+	tests := []struct {
+		space ColorSpace
+		want  []vaxis.Color
+	}{
+		{RGB, []vaxis.Color{black, vaxis.RGBColor(128, 128, 128), white}},
+		// OKLab's midpoint between black and white is lighter
+		{OKLab, []vaxis.Color{black, vaxis.RGBColor(99, 99, 99), white}},
+	}
+	for _, tt := range tests {
+		s, err := Gradient(Horizontal, tt.space, black, white).Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for col, want := range tt.want {
+			// Every row of a column has the same color
+			for row := 0; row < 2; row++ {
+				if got := s.Buffer[row*3+col].Background; got != want {
+					t.Logf("wrong color in space %d at %d,%d, got=%v, want=%v", tt.space, col, row, got.Params(), want.Params())
+					t.Fail()
+				}
+			}
+		}
+	}
+
+	// Indexed colors are interpolated through the xterm palette
+	ctx.Max.Height = 3
+	s, err := Gradient(Vertical, RGB, vaxis.IndexColor(16), vaxis.IndexColor(21)).Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Buffer[3].Background, vaxis.RGBColor(0, 0, 128); got != want {
+		t.Logf("wrong indexed color, got=%v, want=%v", got.Params(), want.Params())
+		t.Fail()
+	}
+}
@@ -44,6 +44,10 @@ type Options struct {
 
 	// Gap controls how much space is placed between each child before the children are sized.
 	Gap uint16
+
+	// Separator, if not nil, is drawn between each child, usually a [Divider] across the main
+	// axis such as [VDivider] in a [Row]. Gap is placed on either side of each separator.
+	Separator vxfw.Widget
 }
 
 // Row returns a [vxfw.Widget] that lays out children horizontally.
@@ -66,6 +70,17 @@ type flex struct {
 var _ vxfw.Widget = flex{}
 
 func (f flex) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if f.options.Separator != nil && len(f.children) > 1 {
+		children := make([]vxfw.Widget, 0, 2*len(f.children)-1)
+		for i, child := range f.children {
+			if i > 0 {
+				children = append(children, f.options.Separator)
+			}
+			children = append(children, child)
+		}
+		f.children = children
+	}
+
 	var totalFlex, maxCross uint16
 	surfaces := make([]vxfw.Surface, len(f.children))
 	used := f.options.Gap * uint16(len(f.children)-1)
@@ -75,6 +90,11 @@ func (f flex) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
 	// Determine how much space they've used on the main axis, and the largest size on the
 	// cross axis.
 	for i, child := range f.children {
+		if f.crosses(child) {
+			// Dividers across the main axis are drawn last, once the cross axis is known
+			used++
+			continue
+		}
 		if c, ok := child.(flexible); ok {
 			// If the flex factor is 0, this is the same as being intrinsically sized
 			factor := c.FlexFactor()
@@ -137,6 +157,26 @@ func (f flex) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
 		maxCross = f.orientation.CrossMax(surface.Size, maxCross)
 	}
 
+	// Dividers across the main axis span the largest child on the cross axis
+	for i, child := range f.children {
+		if !f.crosses(child) {
+			continue
+		}
+
+		cons := instrinsicConstraint(ctx, f.orientation, f.options.CrossAxis)
+		if maxCross > 0 {
+			cons = cons.WithConstraints(f.orientation.Size(0, maxCross), f.orientation.Size(1, maxCross))
+		}
+		surface, err := child.Draw(cons)
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+
+		surfaces[i] = surface
+		used += f.orientation.MainAxis(surface.Size) - 1
+		maxCross = f.orientation.CrossMax(surface.Size, maxCross)
+	}
+
 	// We have all of our surfaces, we know our constraints, it's time to finalize the layout.
 	// Each child is placed within the parent surface based on the layout options.
 	// TODO: Implement MainAxisSize option which allows the main axis to take min (size of all
@@ -222,6 +262,12 @@ func (f flex) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
 	return surface, nil
 }
 
+// crosses reports whether child is a [Divider] across the main axis of f.
+func (f flex) crosses(child vxfw.Widget) bool {
+	d, ok := child.(*Divider)
+	return ok && d.Orientation != f.orientation
+}
+
 // instrinsicConstraint takes a [vxfw.DrawContext] and returns a new one with the main axis
 // unbound, and the cross axis adjusted based on the crossalign.
 // This constraint is used to compute instrinsic sizes of non-[Flexible] children in the first
@@ -0,0 +1,359 @@
+package vxlayout
+
+import (
+	"sort"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// Focusable returns a [vxfw.Widget] which marks widget as a stop in the focus traversal of the
+// [FocusScope] it's in. Focus moves to widget itself, so widget must be drawn as the
+// [vxfw.Surface.Widget] of its surface, as widgets which handle events are.
+//
+// Clicking widget focuses it. Focusable doesn't make widget flexible, so wrap it in [Expanded]
+// rather than the other way around.
+func Focusable(widget vxfw.Widget) vxfw.Widget {
+	return &focusable{widget: widget}
+}
+
+// FocusOrder is like [Focusable], with an explicit place in the traversal order. Widgets with an
+// order greater than 0 come first, from the lowest order to the highest, and then the widgets
+// without one, in the order they're laid out. An order of 0 is the same as [Focusable].
+func FocusOrder(widget vxfw.Widget, order int) vxfw.Widget {
+	return &focusable{widget: widget, order: order}
+}
+
+type focusable struct {
+	widget vxfw.Widget
+	order  int
+
+	// The scopes widget was in when it was last drawn, innermost first, and the nearest ring
+	// around it
+	scopes []*FocusScope
+	ring   *FocusRing
+}
+
+var (
+	_ vxfw.Widget        = &focusable{}
+	_ vxfw.EventCapturer = &focusable{}
+)
+
+func (f *focusable) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	f.scopes, f.ring = f.scopes[:0], nil
+	child, err := f.widget.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	surface := vxfw.Surface{Size: child.Size, Widget: f}
+	surface.AddChild(0, 0, child)
+	return surface, nil
+}
+
+// CaptureEvent tells the scopes f is in that f has focus, since only the focused widget is told
+// when focus changes. Tab is captured, so the widget doesn't see it, and moves focus within the
+// innermost scope.
+func (f *focusable) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+	if mouse, ok := ev.(vaxis.Mouse); ok {
+		// Mouse events are captured by the widgets under the mouse, not the focused ones, so only
+		// a click, which focuses f, says anything about focus
+		if mouse.EventType != vaxis.EventPress || mouse.Button != vaxis.MouseLeftButton {
+			return nil, nil
+		}
+		return f.click(), nil
+	}
+	for _, s := range f.scopes {
+		s.focused = f
+	}
+	key, ok := ev.(vaxis.Key)
+	if !ok || key.EventType == vaxis.EventRelease || len(f.scopes) == 0 {
+		return nil, nil
+	}
+	switch {
+	case key.Matches(vaxis.KeyTab):
+		return f.scopes[0].Next(), nil
+	case key.Matches(vaxis.KeyTab, vaxis.ModShift):
+		return f.scopes[0].Previous(), nil
+	}
+	return nil, nil
+}
+
+// click focuses f, and lights the rings around it if focus moved.
+func (f *focusable) click() vxfw.Command {
+	moved := len(f.scopes) == 0 || f.ring != nil && !f.ring.Focused()
+	for _, s := range f.scopes {
+		moved = moved || s.focused != f
+		s.focused = f
+	}
+	if moved {
+		if t := f.tracker(); t != nil {
+			t.events++
+		}
+		f.ring.light()
+	}
+	return []vxfw.Command{vxfw.FocusWidgetCmd(f.widget), vxfw.RedrawCmd{}}
+}
+
+// tracker returns the tracker shared by the rings and scopes around f, or nil if there are none.
+func (f *focusable) tracker() *focusTracker {
+	switch {
+	case f.ring != nil:
+		return f.ring.tracker
+	case len(f.scopes) > 0:
+		return f.scopes[0].tracker
+	}
+	return nil
+}
+
+// focusStop is a focusable widget in a [FocusScope], and where it was last drawn.
+type focusStop struct {
+	*focusable
+	index int
+	x, y  int
+	size  vxfw.Size
+}
+
+// FocusScope is a [vxfw.Widget] which moves focus between the widgets within Child which are
+// wrapped in [Focusable] or [FocusOrder].
+//
+// While a widget in the scope has focus, the following keys are handled:
+//   - Tab and Shift+Tab: focus the next or previous widget, in order, wrapping around
+//   - Up, Down, Left and Right: focus the nearest widget in that direction, if the focused widget
+//     doesn't handle the key itself
+//
+// Traversal is contained in the scope: Tab never leaves it. A FocusScope within another is part of
+// the outer scope's traversal, until focus moves into it. If focus is in the scope but not on one
+// of its widgets, Tab focuses the first one.
+type FocusScope struct {
+	Child vxfw.Widget
+
+	stops   []focusStop
+	focused *focusable
+
+	// Whether the scope is within another scope or a ring, and the tracker it shares with them
+	enclosed bool
+	tracker  *focusTracker
+	own      focusTracker
+}
+
+// NewFocusScope returns a [FocusScope] of child.
+func NewFocusScope(child vxfw.Widget) *FocusScope {
+	return &FocusScope{Child: child}
+}
+
+// First returns a command which focuses the first widget in the scope, or nil if there are none.
+// The scope must have been drawn.
+func (s *FocusScope) First() vxfw.Command {
+	if len(s.stops) == 0 {
+		return nil
+	}
+	return s.focus(s.stops[0])
+}
+
+// Next returns a command which focuses the widget after the focused one, or the first widget if
+// none of them have focus.
+func (s *FocusScope) Next() vxfw.Command {
+	return s.step(1)
+}
+
+// Previous returns a command which focuses the widget before the focused one, or the last widget
+// if none of them have focus.
+func (s *FocusScope) Previous() vxfw.Command {
+	return s.step(-1)
+}
+
+func (s *FocusScope) step(delta int) vxfw.Command {
+	n := len(s.stops)
+	if n == 0 {
+		return nil
+	}
+	i := s.current()
+	switch {
+	case i >= 0:
+		i = (i + delta + n) % n
+	case delta > 0:
+		i = 0
+	default:
+		i = n - 1
+	}
+	return s.focus(s.stops[i])
+}
+
+// current returns the index of the focused widget in s.stops, or -1.
+func (s *FocusScope) current() int {
+	for i, stop := range s.stops {
+		if stop.focusable == s.focused {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *FocusScope) focus(stop focusStop) vxfw.Command {
+	s.focused = stop.focusable
+
+	// The rings around the widget have focus now, and no others do
+	s.tracker.events++
+	stop.ring.light()
+
+	return []vxfw.Command{vxfw.FocusWidgetCmd(stop.widget), vxfw.ConsumeAndRedraw()}
+}
+
+// Direction is a direction to move focus in, with [FocusScope.Move].
+type Direction int
+
+const (
+	FocusUp Direction = iota
+	FocusDown
+	FocusLeft
+	FocusRight
+)
+
+// Move returns a command which focuses the widget nearest to the focused one in direction dir, or
+// nil if there isn't one. Widgets which overlap the focused one across dir are nearer than those
+// which don't.
+func (s *FocusScope) Move(dir Direction) vxfw.Command {
+	i := s.current()
+	if i < 0 {
+		return nil
+	}
+	from := s.stops[i]
+
+	best, bestScore := -1, 0
+	for j, to := range s.stops {
+		if j == i {
+			continue
+		}
+		// The distance along dir, from the edge of from to the edge of to, and the distance
+		// across it between the two, which is 0 if they overlap
+		var along, across int
+		switch dir {
+		case FocusUp:
+			along = from.y - (to.y + int(to.size.Height))
+			across = gap(from.x, int(from.size.Width), to.x, int(to.size.Width))
+		case FocusDown:
+			along = to.y - (from.y + int(from.size.Height))
+			across = gap(from.x, int(from.size.Width), to.x, int(to.size.Width))
+		case FocusLeft:
+			along = from.x - (to.x + int(to.size.Width))
+			across = gap(from.y, int(from.size.Height), to.y, int(to.size.Height))
+		case FocusRight:
+			along = to.x - (from.x + int(from.size.Width))
+			across = gap(from.y, int(from.size.Height), to.y, int(to.size.Height))
+		}
+		if along < 0 {
+			continue
+		}
+		score := along + 2*across
+		if across > 0 {
+			// Widgets which overlap always win
+			score += 1 << 16
+		}
+		if best < 0 || score < bestScore || score == bestScore && to.index < s.stops[best].index {
+			best, bestScore = j, score
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return s.focus(s.stops[best])
+}
+
+// gap returns the distance between the ranges [a, a+alen) and [b, b+blen), or 0 if they overlap.
+func gap(a, alen, b, blen int) int {
+	switch {
+	case b >= a+alen:
+		return b - (a + alen) + 1
+	case a >= b+blen:
+		return a - (b + blen) + 1
+	}
+	return 0
+}
+
+var (
+	_ vxfw.Widget        = &FocusScope{}
+	_ vxfw.EventCapturer = &FocusScope{}
+)
+
+func (s *FocusScope) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	surface := vxfw.Surface{Size: ctx.Min, Widget: s}
+	s.stops = s.stops[:0]
+	s.enclosed, s.tracker = false, &s.own
+	if s.Child == nil {
+		return surface, nil
+	}
+	child, err := s.Child.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	surface.Size = child.Size
+	surface.AddChild(0, 0, child)
+
+	s.stops = s.findStops(child, 0, 0, s.stops)
+	sort.SliceStable(s.stops, func(i, j int) bool {
+		a, b := s.stops[i].order, s.stops[j].order
+		return a > 0 && (b <= 0 || a < b)
+	})
+	return surface, nil
+}
+
+// findStops appends the focusable widgets in surface, which is at x, y in the scope, to stops in
+// the order they're laid out. The rings and scopes within surface share the scope's tracker.
+func (s *FocusScope) findStops(surface vxfw.Surface, x, y int, stops []focusStop) []focusStop {
+	switch w := surface.Widget.(type) {
+	case *FocusRing:
+		w.enclosed, w.tracker = true, s.tracker
+	case *FocusScope:
+		w.enclosed, w.tracker = true, s.tracker
+	}
+	if f, ok := surface.Widget.(*focusable); ok {
+		for _, stop := range stops {
+			if stop.focusable == f {
+				// A widget drawn twice, such as a pinned sticky header, is one stop
+				return stops
+			}
+		}
+		f.scopes = append(f.scopes, s)
+		stops = append(stops, focusStop{focusable: f, index: len(stops), x: x, y: y, size: surface.Size})
+	}
+	for _, child := range surface.Children {
+		stops = s.findStops(child.Surface, x+child.Origin.Col, y+child.Origin.Row, stops)
+	}
+	return stops
+}
+
+// CaptureEvent forgets which widget had focus, since the widget which has focus now tells the
+// scope when it captures the event. The outermost scope also counts the event for the rings
+// within it, so rings which don't see it know they've lost focus.
+func (s *FocusScope) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+	if _, ok := ev.(vaxis.Mouse); ok {
+		return nil, nil
+	}
+	s.focused = nil
+	if !s.enclosed && s.tracker != nil {
+		s.tracker.events++
+	}
+	return nil, nil
+}
+
+func (s *FocusScope) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	key, ok := ev.(vaxis.Key)
+	if !ok || key.EventType == vaxis.EventRelease {
+		return nil, nil
+	}
+	switch {
+	case key.Matches(vaxis.KeyTab):
+		return s.Next(), nil
+	case key.Matches(vaxis.KeyTab, vaxis.ModShift):
+		return s.Previous(), nil
+	case key.Matches(vaxis.KeyUp):
+		return s.Move(FocusUp), nil
+	case key.Matches(vaxis.KeyDown):
+		return s.Move(FocusDown), nil
+	case key.Matches(vaxis.KeyLeft):
+		return s.Move(FocusLeft), nil
+	case key.Matches(vaxis.KeyRight):
+		return s.Move(FocusRight), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,124 @@
+package vxlayout
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+)
+
+// focusTarget returns the widget cmd focuses, or nil.
+func focusTarget(cmd vxfw.Command) vxfw.Widget {
+	switch cmd := cmd.(type) {
+	case vxfw.FocusWidgetCmd:
+		return cmd
+	case []vxfw.Command:
+		for _, c := range cmd {
+			if w := focusTarget(c); w != nil {
+				return w
+			}
+		}
+	}
+	return nil
+}
+
+func TestFocusScope(t *testing.T) {
+	// a b
+	// c d
+	// with b first, by its explicit order
+	a, b, c, d := text.New("a"), text.New("b"), text.New("c"), text.New("d")
+	scope := NewFocusScope(Column([]vxfw.Widget{
+		Row([]vxfw.Widget{Focusable(a), FocusOrder(b, 1)}, Options{Gap: 1}),
+		Row([]vxfw.Widget{Focusable(c), Focusable(d)}, Options{Gap: 1}),
+	}, Options{CrossAxis: CrossAxisStart}))
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 3, Height: 2}, Characters: vaxis.Characters}
+	surface, err := scope.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wrapper finds the focusable wrapping w in the drawn surface, to send it events
+	var wrapper func(s vxfw.Surface, w vxfw.Widget) *focusable
+	wrapper = func(s vxfw.Surface, w vxfw.Widget) *focusable {
+		if f, ok := s.Widget.(*focusable); ok && f.widget == w {
+			return f
+		}
+		for _, child := range s.Children {
+			if f := wrapper(child.Surface, w); f != nil {
+				return f
+			}
+		}
+		return nil
+	}
+	// send delivers key to the scope as the app would, with w focused
+	send := func(w vxfw.Widget, key vaxis.Key) vxfw.Widget {
+		scope.CaptureEvent(key)
+		cmd, _ := wrapper(surface, w).CaptureEvent(key)
+		if cmd == nil {
+			cmd, _ = scope.HandleEvent(key, vxfw.BubblePhase)
+		}
+		return focusTarget(cmd)
+	}
+
+	if got := focusTarget(scope.First()); got != b {
+		t.Logf("wrong first widget, got=%v, want=b", got)
+		t.Fail()
+	}
+
+	tab := vaxis.Key{Keycode: vaxis.KeyTab}
+	backtab := vaxis.Key{Keycode: vaxis.KeyTab, Modifiers: vaxis.ModShift}
+	tests := []struct {
+		name string
+		from vxfw.Widget
+		key  vaxis.Key
+		want vxfw.Widget
+	}{
+		{"tab after explicit order", b, tab, a},
+		{"tab in layout order", a, tab, c},
+		{"tab wraps around", d, tab, b},
+		{"shift+tab", c, backtab, a},
+		{"shift+tab wraps around", b, backtab, d},
+		{"right", a, vaxis.Key{Keycode: vaxis.KeyRight}, b},
+		{"down", b, vaxis.Key{Keycode: vaxis.KeyDown}, d},
+		{"left", d, vaxis.Key{Keycode: vaxis.KeyLeft}, c},
+		{"up", c, vaxis.Key{Keycode: vaxis.KeyUp}, a},
+		{"nothing above", a, vaxis.Key{Keycode: vaxis.KeyUp}, nil},
+	}
+	for _, test := range tests {
+		if got := send(test.from, test.key); got != test.want {
+			t.Logf("%s: wrong widget focused, got=%v, want=%v", test.name, got, test.want)
+			t.Fail()
+		}
+	}
+
+	// The mouse passing over d doesn't focus it, so focus moves on from a
+	mouse := vaxis.Mouse{EventType: vaxis.EventMotion, Button: vaxis.MouseNoButton}
+	scope.CaptureEvent(mouse)
+	wrapper(surface, d).CaptureEvent(mouse)
+	if got := focusTarget(scope.Next()); got != c {
+		t.Logf("wrong widget focused after the mouse moved, got=%v, want=c", got)
+		t.Fail()
+	}
+
+	// Releasing a key doesn't move focus again
+	release := func(k vaxis.Key) vaxis.Key {
+		k.EventType = vaxis.EventRelease
+		return k
+	}
+	if got := send(a, release(tab)); got != nil {
+		t.Logf("releasing tab moved focus to %v", got)
+		t.Fail()
+	}
+	if got := send(a, release(vaxis.Key{Keycode: vaxis.KeyRight})); got != nil {
+		t.Logf("releasing right moved focus to %v", got)
+		t.Fail()
+	}
+
+	// With focus elsewhere in the scope, tab starts from the first widget
+	scope.CaptureEvent(tab)
+	if cmd, _ := scope.HandleEvent(tab, vxfw.TargetPhase); focusTarget(cmd) != b {
+		t.Logf("wrong widget focused from outside, got=%v, want=b", focusTarget(cmd))
+		t.Fail()
+	}
+}
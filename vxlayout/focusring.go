@@ -0,0 +1,153 @@
+package vxlayout
+
+import (
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/theme"
+)
+
+// focusTracker counts the events sent to the focused widget, such as keys, and the times focus
+// moves, for the rings and scopes which share it. The outermost [FocusRing] or [FocusScope]
+// owns the tracker and counts each event which passes through it, and each ring the event passes
+// through records the count, so a ring has focus if it saw the last one.
+type focusTracker struct {
+	events uint64
+}
+
+// FocusRing is a [vxfw.Widget] which draws a border around Child, highlighted while Child or one
+// of its descendants has focus, so it's clear which part of an application is active.
+//
+// Only the focused widget is told when focus changes, so a ring notices focus has moved into it
+// when the next key reaches it, or when a [FocusScope] or a click on a [Focusable] widget moves
+// focus into it. It notices focus has left when another ring or an enclosing FocusScope sees an
+// event it doesn't, so wrap an application's rings in a FocusScope.
+type FocusRing struct {
+	Child  vxfw.Widget
+	Border Border
+
+	// FocusRole styles the border while the ring has focus, and BlurRole while it doesn't. If a
+	// role is empty, FocusStyle or BlurStyle is used instead.
+	FocusRole  theme.Role
+	FocusStyle vaxis.Style
+	BlurRole   theme.Role
+	BlurStyle  vaxis.Style
+
+	// Focus, if not nil, overrides roles of the theme for Child while the ring has focus, so
+	// Child can change its own style too. See [theme.Override].
+	Focus map[theme.Role]vaxis.Style
+
+	// The nearest ring around this one, whether the ring is within another ring or a scope, and
+	// the tracker it shares with them
+	parent   *FocusRing
+	enclosed bool
+	tracker  *focusTracker
+	own      focusTracker
+
+	// The count of focus events when the ring last saw one, and whether the ring had focus when
+	// it was last drawn
+	seen  uint64
+	drawn bool
+}
+
+// NewFocusRing returns a [FocusRing] of child with a rounded border, in the [theme.Primary] role
+// while it has focus and the [theme.Border] role while it doesn't.
+func NewFocusRing(child vxfw.Widget) *FocusRing {
+	return &FocusRing{
+		Child:     child,
+		Border:    RoundedBorder,
+		FocusRole: theme.Primary,
+		BlurRole:  theme.Border,
+	}
+}
+
+// Focused reports whether Child or one of its descendants has focus.
+func (r *FocusRing) Focused() bool {
+	return r.tracker != nil && r.seen != 0 && r.seen == r.tracker.events
+}
+
+// light records that r and the rings around it have focus.
+func (r *FocusRing) light() {
+	for ; r != nil; r = r.parent {
+		r.seen = r.tracker.events
+	}
+}
+
+var (
+	_ vxfw.Widget        = &FocusRing{}
+	_ vxfw.EventCapturer = &FocusRing{}
+)
+
+func (r *FocusRing) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	// Enclosing rings and scopes share their tracker once they've drawn r
+	r.drawn = r.Focused()
+	r.parent, r.enclosed, r.tracker = nil, false, &r.own
+
+	box := &Container{Child: r.Child, Border: r.Border, BorderRole: r.BlurRole, BorderStyle: r.BlurStyle}
+	if r.drawn {
+		box.BorderRole, box.BorderStyle = r.FocusRole, r.FocusStyle
+		if r.Focus != nil && r.Child != nil {
+			box.Child = theme.Override(r.Focus, r.Child)
+		}
+	}
+	child, err := box.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+
+	surface := vxfw.Surface{Size: child.Size, Widget: r}
+	surface.AddChild(0, 0, child)
+	r.enclose(child, true)
+	return surface, nil
+}
+
+// enclose records that the rings, scopes and focusable widgets in s are within r, and share its
+// tracker. nearest is whether there's no other ring between s and r.
+func (r *FocusRing) enclose(s vxfw.Surface, nearest bool) {
+	switch w := s.Widget.(type) {
+	case *FocusRing:
+		w.enclosed, w.tracker = true, r.tracker
+		if nearest {
+			w.parent = r
+		}
+		nearest = false
+	case *FocusScope:
+		w.enclosed, w.tracker = true, r.tracker
+	case *focusable:
+		if nearest {
+			w.ring = r
+		}
+	}
+	for _, child := range s.Children {
+		r.enclose(child.Surface, nearest)
+	}
+}
+
+// CaptureEvent records that the ring has focus, since the event passed through it on the way to
+// the focused widget.
+func (r *FocusRing) CaptureEvent(ev vaxis.Event) (vxfw.Command, error) {
+	if _, ok := ev.(vaxis.Mouse); ok || r.tracker == nil {
+		// Mouse events are captured by the widgets under the mouse, not the focused ones
+		return nil, nil
+	}
+	if !r.enclosed {
+		r.tracker.events++
+	}
+	r.seen = r.tracker.events
+	if !r.drawn {
+		return vxfw.RedrawCmd{}, nil
+	}
+	return nil, nil
+}
+
+func (r *FocusRing) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	if _, ok := ev.(vaxis.FocusIn); ok {
+		// The ring itself was focused
+		if r.tracker == nil {
+			return nil, nil
+		}
+		r.tracker.events++
+		r.light()
+		return vxfw.RedrawCmd{}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,112 @@
+package vxlayout
+
+import (
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp"
+	"github.com/avidal/vxexp/theme"
+)
+
+func TestFocusRing(t *testing.T) {
+	focus := vaxis.Style{Foreground: vaxis.IndexColor(1)}
+	blur := vaxis.Style{Foreground: vaxis.IndexColor(2)}
+	highlight := vaxis.Style{Background: vaxis.IndexColor(3)}
+
+	// Each ring holds a focusable widget and a label
+	ring := func(w vxfw.Widget) (*FocusRing, *focusable) {
+		f := Focusable(w).(*focusable)
+		return &FocusRing{
+			Child:      Row([]vxfw.Widget{f, theme.Text(":", theme.Normal)}, Options{}),
+			Border:     LineBorder,
+			FocusStyle: focus,
+			BlurStyle:  blur,
+			Focus:      map[theme.Role]vaxis.Style{theme.Normal: highlight},
+		}, f
+	}
+	a, b := theme.Text("a", theme.Normal), theme.Text("b", theme.Normal)
+	left, fa := ring(a)
+	right, fb := ring(b)
+	scope := NewFocusScope(Row([]vxfw.Widget{Expanded(left, 1), Expanded(right, 1)}, Options{}))
+
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 8, Height: 3}, Characters: vaxis.Characters}
+	// check draws the scope, and checks the border and child of each ring are styled for focus
+	// only if the ring is in want
+	check := func(name string, want ...*FocusRing) {
+		t.Helper()
+		surface, err := scope.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		flat := vxexp.Flatten(surface)
+		for i, r := range []*FocusRing{left, right} {
+			focused := false
+			for _, w := range want {
+				focused = focused || w == r
+			}
+			border, child := focus, highlight
+			if !focused {
+				border, child = blur, vaxis.Style{}
+			}
+			if r.Focused() != focused {
+				t.Logf("%s: ring %d has wrong focus, got=%v, want=%v", name, i, r.Focused(), focused)
+				t.Fail()
+			}
+			if got := flat.Buffer[4*i].Style; got != border {
+				t.Logf("%s: ring %d has wrong border style, got=%v, want=%v", name, i, got, border)
+				t.Fail()
+			}
+			if got := flat.Buffer[8+4*i+1].Style; got != child {
+				t.Logf("%s: ring %d has wrong child style, got=%v, want=%v", name, i, got, child)
+				t.Fail()
+			}
+		}
+	}
+
+	check("before focus")
+	scope.First()
+	check("first", left)
+	scope.Next()
+	check("next", right)
+
+	// A key passes through the scope and the ring around the focused widget
+	key := vaxis.Key{Keycode: 'x'}
+	scope.CaptureEvent(key)
+	right.CaptureEvent(key)
+	fb.CaptureEvent(key)
+	check("key", right)
+
+	// Clicking the label in the other ring doesn't move focus
+	click := vaxis.Mouse{Button: vaxis.MouseLeftButton, EventType: vaxis.EventPress}
+	scope.CaptureEvent(click)
+	left.CaptureEvent(click)
+	check("click label", right)
+
+	// Clicking the widget in the other ring focuses it
+	scope.CaptureEvent(click)
+	left.CaptureEvent(click)
+	cmd, _ := fa.CaptureEvent(click)
+	if got := focusTarget(cmd); got != a {
+		t.Logf("clicking a focusable widget focused %v, want a", got)
+		t.Fail()
+	}
+	check("click widget", left)
+
+	// A key which only passes through the scope was sent to a widget outside the rings
+	scope.CaptureEvent(key)
+	check("outside")
+
+	// Rings in another scope have a count of their own
+	other, _ := ring(theme.Text("c", theme.Normal))
+	otherScope := NewFocusScope(other)
+	if _, err := otherScope.Draw(ctx); err != nil {
+		t.Fatal(err)
+	}
+	otherScope.First()
+	check("other scope")
+	if !other.Focused() {
+		t.Log("ring in the other scope should have focus")
+		t.Fail()
+	}
+}
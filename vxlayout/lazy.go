@@ -0,0 +1,357 @@
+package vxlayout
+
+import (
+	"math"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// LazyColumn is a [vxfw.Widget] which lays out Count children vertically, like a [Column], but
+// only builds and draws the children in view. Children are built by calling Builder with their
+// index, and kept while they stay in view, so they can keep their own state.
+//
+// The heights of children are measured as they're drawn. Children which haven't been drawn are
+// assumed to be Estimate rows tall, which only affects [LazyColumn.Offset] and
+// [LazyColumn.ContentHeight], and scrolling past them.
+//
+// The scroll position is kept as a child, the anchor, and the number of its rows scrolled out of
+// view. Children are laid out from the anchor, so children above it changing height doesn't move
+// the children in view. If StickToEnd is set and the end of the column is in view, the column
+// stays scrolled to the end as children are added, as in a chat transcript.
+//
+// If Headers is set, children which Builder wraps in [Sticky] stick to the top of the view while
+// their section is in view. To find the header of the section at the top of the view, children
+// above it are built, without being drawn, until a header is found, so set Headers only if
+// headers are frequent enough for that to be cheap.
+//
+// A LazyColumn must have a bounded height. It scrolls with the mouse wheel.
+type LazyColumn struct {
+	Count   int
+	Builder func(i int) vxfw.Widget
+	// Estimate is the height of children which haven't been drawn yet. An Estimate of 0 is the
+	// same as 1.
+	Estimate uint16
+	// Gap is the number of rows between children.
+	Gap uint16
+
+	StickToEnd bool
+	// Headers pins [Sticky] children to the top of the view.
+	Headers bool
+
+	anchor int
+	offset int
+	atEnd  bool
+
+	heights []uint32
+	kinds   []kind
+	built   map[int]vxfw.Widget
+}
+
+// kind is whether a child is a sticky header, or unknown if it hasn't been built.
+type kind uint8
+
+const (
+	unknownKind kind = iota
+	plainKind
+	headerKind
+)
+
+// NewLazyColumn returns a [LazyColumn] of count children built by builder.
+func NewLazyColumn(count int, builder func(i int) vxfw.Widget) *LazyColumn {
+	return &LazyColumn{Count: count, Builder: builder, Estimate: 1}
+}
+
+// height returns the height of child i, including the gap after it. Children which haven't been
+// measured are assumed to be l.Estimate rows tall.
+func (l *LazyColumn) height(i int) int {
+	if i < len(l.heights) && l.heights[i] > 0 {
+		return int(l.heights[i]) - 1 + int(l.Gap)
+	}
+	if l.Estimate == 0 {
+		return 1 + int(l.Gap)
+	}
+	return int(l.Estimate) + int(l.Gap)
+}
+
+// Anchor returns the index of the child at the top of the view, and the number of its rows
+// scrolled out of view.
+func (l *LazyColumn) Anchor() (index, offset int) { return l.anchor, l.offset }
+
+// ScrollTo scrolls so child i is at the top of the view.
+func (l *LazyColumn) ScrollTo(i int) {
+	l.anchor, l.offset = clampIndex(i, l.Count), 0
+	l.atEnd = false
+}
+
+// ScrollToEnd scrolls so the last child is at the bottom of the view.
+func (l *LazyColumn) ScrollToEnd() {
+	l.anchor, l.offset = clampIndex(l.Count-1, l.Count), math.MaxInt32
+	l.atEnd = true
+}
+
+// ScrollBy scrolls by rows, which are negative to scroll up.
+func (l *LazyColumn) ScrollBy(rows int) {
+	l.anchor = clampIndex(l.anchor, l.Count)
+	pos := l.offset + rows
+	for pos < 0 && l.anchor > 0 {
+		l.anchor--
+		pos += l.height(l.anchor)
+	}
+	for l.anchor < l.Count-1 && pos >= l.height(l.anchor) {
+		pos -= l.height(l.anchor)
+		l.anchor++
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	l.offset = pos
+	if rows < 0 {
+		l.atEnd = false
+	}
+}
+
+// Offset returns the number of rows above the view, using the estimated height of children which
+// haven't been drawn.
+func (l *LazyColumn) Offset() int {
+	offset := l.offset
+	for i := 0; i < l.anchor && i < l.Count; i++ {
+		offset += l.height(i)
+	}
+	return offset
+}
+
+// ContentHeight returns the height of all of the children, using the estimated height of children
+// which haven't been drawn. Together with [LazyColumn.Offset], it's what a scrollbar needs.
+func (l *LazyColumn) ContentHeight() int {
+	total := 0
+	for i := 0; i < l.Count; i++ {
+		total += l.height(i)
+	}
+	return total
+}
+
+// SetOffset scrolls so there are offset rows above the view, using the estimated height of
+// children which haven't been drawn.
+func (l *LazyColumn) SetOffset(offset int) {
+	l.anchor, l.offset = 0, 0
+	l.atEnd = false
+	l.ScrollBy(offset)
+}
+
+// Invalidate discards the built children at indexes, and their heights, so they're built and
+// measured again the next time they're in view. Call it when the content of children changes.
+func (l *LazyColumn) Invalidate(indexes ...int) {
+	for _, i := range indexes {
+		delete(l.built, i)
+		if i >= 0 && i < len(l.heights) {
+			l.heights[i] = 0
+		}
+		if i >= 0 && i < len(l.kinds) {
+			l.kinds[i] = unknownKind
+		}
+	}
+}
+
+// Reset discards all of the built children and their heights, for example when children are
+// inserted or removed other than at the end. The scroll position is kept.
+func (l *LazyColumn) Reset() {
+	l.built, l.heights, l.kinds = nil, nil, nil
+}
+
+// child draws child i, and records its height.
+func (l *LazyColumn) child(ctx vxfw.DrawContext, i int, built map[int]vxfw.Widget) (vxfw.Surface, error) {
+	w := l.build(i)
+	built[i] = w
+
+	s, err := w.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	for len(l.heights) <= i {
+		l.heights = append(l.heights, 0)
+	}
+	// Heights are kept plus one, so 0 means the child hasn't been measured
+	l.heights[i] = uint32(s.Size.Height) + 1
+	return s, nil
+}
+
+// build returns child i, built again only if it wasn't kept, and records its kind.
+func (l *LazyColumn) build(i int) vxfw.Widget {
+	w, ok := l.built[i]
+	if !ok {
+		w = l.Builder(i)
+	}
+	for len(l.kinds) <= i {
+		l.kinds = append(l.kinds, unknownKind)
+	}
+	l.kinds[i] = plainKind
+	if _, ok := w.(*sticky); ok {
+		l.kinds[i] = headerKind
+	}
+	return w
+}
+
+// headerBefore returns the index of the last sticky header before child i, building the children
+// before i whose kind isn't known yet. Headers are kept until they're drawn.
+func (l *LazyColumn) headerBefore(i int) (int, bool) {
+	for i--; i >= 0; i-- {
+		if i >= len(l.kinds) || l.kinds[i] == unknownKind {
+			w := l.build(i)
+			if l.kinds[i] == headerKind {
+				if l.built == nil {
+					l.built = make(map[int]vxfw.Widget)
+				}
+				l.built[i] = w
+			}
+		}
+		if l.kinds[i] == headerKind {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+var _ vxfw.Widget = &LazyColumn{}
+
+func (l *LazyColumn) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if ctx.Max.HasUnboundedHeight() {
+		panic("LazyColumn must have a bounded height")
+	}
+
+	width, height := ctx.Max.Width, int(ctx.Max.Height)
+	surface := vxfw.Surface{Size: vxfw.Size{Width: width, Height: uint16(height)}, Widget: l}
+	if l.Count <= 0 || l.Builder == nil || height == 0 {
+		return surface, nil
+	}
+	if len(l.heights) > l.Count {
+		l.heights = l.heights[:l.Count]
+	}
+	if len(l.kinds) > l.Count {
+		l.kinds = l.kinds[:l.Count]
+	}
+
+	if l.StickToEnd && l.atEnd {
+		l.ScrollToEnd()
+	}
+	l.anchor = clampIndex(l.anchor, l.Count)
+
+	childCtx := ctx.WithConstraints(vxfw.Size{}, vxfw.Size{Width: width, Height: math.MaxUint16})
+	built := make(map[int]vxfw.Widget)
+
+	// Lay out children from the anchor down, until the view is full
+	type placed struct {
+		index   int
+		surface vxfw.Surface
+	}
+	var children []placed
+	bottom := 0
+	for i := l.anchor; i < l.Count && bottom < height; i++ {
+		s, err := l.child(childCtx, i, built)
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+		children = append(children, placed{index: i, surface: s})
+		if i == l.anchor {
+			// The anchor may be shorter than the offset into it, if it shrank or the
+			// offset was past the end
+			if l.offset >= l.height(i) {
+				l.offset = l.height(i) - 1
+			}
+			if l.offset < 0 {
+				l.offset = 0
+			}
+			bottom = -l.offset
+		}
+		bottom += l.height(i)
+	}
+
+	// If the end of the column is in view and there's space below it, scroll up to fill it
+	last := children[len(children)-1].index
+	gap := -1
+	if last == l.Count-1 {
+		gap = height - (bottom - int(l.Gap))
+	}
+	l.atEnd = gap >= 0
+	for gap > 0 && (l.offset > 0 || l.anchor > 0) {
+		if l.offset > 0 {
+			d := l.offset
+			if d > gap {
+				d = gap
+			}
+			l.offset -= d
+			gap -= d
+			continue
+		}
+		l.anchor--
+		s, err := l.child(childCtx, l.anchor, built)
+		if err != nil {
+			return vxfw.Surface{}, err
+		}
+		children = append([]placed{{index: l.anchor, surface: s}}, children...)
+		l.offset = l.height(l.anchor)
+	}
+
+	var headers []header
+	y := -l.offset
+	for _, c := range children {
+		if y+int(c.surface.Size.Height) > 0 && y < height {
+			surface.AddChild(0, y, c.surface)
+		}
+		if l.Headers {
+			headers = findHeaders(c.surface, 0, y, headers)
+		}
+		y += l.height(c.index)
+	}
+	if !l.Headers {
+		l.built = built
+		return surface, nil
+	}
+
+	// The header of the section at the top of the view may be above the children drawn
+	first := children[0].index
+	if len(headers) == 0 || headers[0].y > 0 {
+		if i, ok := l.headerBefore(first); ok {
+			s, err := l.child(childCtx, i, built)
+			if err != nil {
+				return vxfw.Surface{}, err
+			}
+			y := -l.offset
+			for j := first - 1; j >= i; j-- {
+				y -= l.height(j)
+			}
+			headers = append([]header{{y: y, surface: s}}, headers...)
+		}
+	}
+	pinHeader(&surface, headers)
+	l.built = built
+
+	return surface, nil
+}
+
+func (l *LazyColumn) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok || mouse.EventType != vaxis.EventPress {
+		return nil, nil
+	}
+	switch mouse.Button {
+	case vaxis.MouseWheelUp:
+		l.ScrollBy(-3)
+		return vxfw.ConsumeAndRedraw(), nil
+	case vaxis.MouseWheelDown:
+		l.ScrollBy(3)
+		return vxfw.ConsumeAndRedraw(), nil
+	}
+	return nil, nil
+}
+
+// clampIndex returns i clamped to the indexes of a slice of length n, or 0 if n is 0.
+func clampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
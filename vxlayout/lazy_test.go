@@ -0,0 +1,149 @@
+package vxlayout
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp"
+)
+
+// lazyRows draws l and returns its rows, with "_" for cells without a grapheme.
+func lazyRows(t *testing.T, l *LazyColumn, height uint16) []string {
+	t.Helper()
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 5, Height: height}, Characters: vaxis.Characters}
+	s, err := l.Draw(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graphemes(vxexp.Flatten(s))
+}
+
+func TestLazyColumn(t *testing.T) {
+	// Child i is i%3+1 rows tall
+	lines := func(i int) []string {
+		out := make([]string, i%3+1)
+		for j := range out {
+			out[j] = strconv.Itoa(i)
+		}
+		return out
+	}
+	built := 0
+	l := NewLazyColumn(100000, func(i int) vxfw.Widget {
+		built++
+		return text.New(strings.Join(lines(i), "\n"))
+	})
+
+	want := []string{"0____", "1____", "1____", "2____"}
+	got := lazyRows(t, l, 4)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong row %d, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+	if built != 3 {
+		t.Logf("wrong number of children built, got=%d, want=3", built)
+		t.Fail()
+	}
+
+	// Scrolling uses the measured heights
+	l.ScrollBy(2)
+	if index, offset := l.Anchor(); index != 1 || offset != 1 {
+		t.Logf("wrong anchor after scrolling, got=%d+%d, want=1+1", index, offset)
+		t.Fail()
+	}
+
+	// Children in view are kept, and only new ones are built
+	built = 0
+	want = []string{"1____", "2____", "2____", "2____"}
+	got = lazyRows(t, l, 4)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong scrolled row %d, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+	if built != 0 {
+		t.Logf("wrong number of children rebuilt, got=%d, want=0", built)
+		t.Fail()
+	}
+
+	// Scrolling past the end stops with the last child at the bottom
+	l.ScrollToEnd()
+	want = []string{"99998", "99998", "99998", "99999"}
+	got = lazyRows(t, l, 4)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong row %d at the end, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+	if index, offset := l.Anchor(); index != 99998 || offset != 0 {
+		t.Logf("wrong anchor at the end, got=%d+%d, want=99998+0", index, offset)
+		t.Fail()
+	}
+}
+
+func TestLazyColumnAnchor(t *testing.T) {
+	heights := []int{1, 1, 1, 1, 1, 1}
+	l := NewLazyColumn(len(heights), func(i int) vxfw.Widget {
+		return text.New(strings.TrimSuffix(strings.Repeat(strconv.Itoa(i)+"\n", heights[i]), "\n"))
+	})
+	l.ScrollTo(3)
+	lazyRows(t, l, 2)
+
+	// A child above the anchor growing doesn't move the children in view
+	heights[1] = 3
+	l.Invalidate(1)
+	want := []string{"3____", "4____"}
+	got := lazyRows(t, l, 2)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong row %d, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+
+	// Sticking to the end follows new children
+	l.StickToEnd = true
+	l.ScrollToEnd()
+	lazyRows(t, l, 2)
+	heights = append(heights, 1)
+	l.Count++
+	want = []string{"5____", "6____"}
+	got = lazyRows(t, l, 2)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Logf("wrong row %d after adding a child, got=%q, want=%q", i, got[i], want[i])
+			t.Fail()
+		}
+	}
+}
+
+func TestLazyColumnBuilds(t *testing.T) {
+	built := 0
+	l := NewLazyColumn(50000, func(i int) vxfw.Widget {
+		built++
+		return text.New(strconv.Itoa(i))
+	})
+
+	// Without headers, only the children in view are built
+	l.ScrollToEnd()
+	lazyRows(t, l, 4)
+	if built != 4 {
+		t.Logf("wrong number of children built at the end, got=%d, want=4", built)
+		t.Fail()
+	}
+
+	built = 0
+	l.Reset()
+	lazyRows(t, l, 4)
+	if built != 4 {
+		t.Logf("wrong number of children built after reset, got=%d, want=4", built)
+		t.Fail()
+	}
+}
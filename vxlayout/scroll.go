@@ -0,0 +1,114 @@
+package vxlayout
+
+import (
+	"math"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+)
+
+// Scroll is a [vxfw.Widget] which scrolls Child vertically. Child is drawn with an unbounded
+// height, and all of it is drawn every time, so for a large number of children use a
+// [LazyColumn] instead.
+//
+// Widgets like [Column] which take all of an unbounded height are as tall as their children.
+// Children wrapped in [Sticky] stick to the top of the view while their section is in view.
+//
+// A Scroll must have a bounded height. It scrolls with the mouse wheel.
+type Scroll struct {
+	Child vxfw.Widget
+
+	offset  int
+	content int
+	height  int
+}
+
+// NewScroll returns a [Scroll] of child.
+func NewScroll(child vxfw.Widget) *Scroll {
+	return &Scroll{Child: child}
+}
+
+// Offset returns the number of rows above the view.
+func (s *Scroll) Offset() int { return s.offset }
+
+// ContentHeight returns the height of Child when it was last drawn. Together with
+// [Scroll.Offset], it's what a scrollbar needs.
+func (s *Scroll) ContentHeight() int { return s.content }
+
+// SetOffset scrolls so there are offset rows above the view.
+func (s *Scroll) SetOffset(offset int) {
+	s.offset = offset
+	s.clamp()
+}
+
+// ScrollBy scrolls by rows, which are negative to scroll up.
+func (s *Scroll) ScrollBy(rows int) {
+	s.SetOffset(s.offset + rows)
+}
+
+// clamp keeps the offset within the content, once the content has been drawn.
+func (s *Scroll) clamp() {
+	if s.height > 0 && s.offset > s.content-s.height {
+		s.offset = s.content - s.height
+	}
+	if s.offset < 0 {
+		s.offset = 0
+	}
+}
+
+var _ vxfw.Widget = &Scroll{}
+
+func (s *Scroll) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	if ctx.Max.HasUnboundedHeight() {
+		panic("Scroll must have a bounded height")
+	}
+	surface := vxfw.Surface{Size: ctx.Max, Widget: s}
+	if s.Child == nil {
+		return surface, nil
+	}
+
+	child, err := s.Child.Draw(ctx.WithConstraints(
+		vxfw.Size{Width: ctx.Min.Width},
+		vxfw.Size{Width: ctx.Max.Width, Height: math.MaxUint16},
+	))
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	s.content, s.height = contentHeight(child), int(ctx.Max.Height)
+	s.clamp()
+
+	surface.AddChild(0, -s.offset, child)
+	pinHeader(&surface, findHeaders(child, 0, -s.offset, nil))
+	return surface, nil
+}
+
+func (s *Scroll) HandleEvent(ev vaxis.Event, ph vxfw.EventPhase) (vxfw.Command, error) {
+	mouse, ok := ev.(vaxis.Mouse)
+	if !ok || mouse.EventType != vaxis.EventPress {
+		return nil, nil
+	}
+	switch mouse.Button {
+	case vaxis.MouseWheelUp:
+		s.ScrollBy(-3)
+		return vxfw.ConsumeAndRedraw(), nil
+	case vaxis.MouseWheelDown:
+		s.ScrollBy(3)
+		return vxfw.ConsumeAndRedraw(), nil
+	}
+	return nil, nil
+}
+
+// contentHeight returns the height of s, or the bottom of its lowest child if s took all of an
+// unbounded height.
+func contentHeight(s vxfw.Surface) int {
+	if s.Size.Height != math.MaxUint16 {
+		return int(s.Size.Height)
+	}
+	bottom := 0
+	for _, child := range s.Children {
+		if b := child.Origin.Row + contentHeight(child.Surface); b > bottom {
+			bottom = b
+		}
+	}
+	return bottom
+}
@@ -0,0 +1,96 @@
+package vxlayout
+
+import (
+	"sort"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"github.com/avidal/vxexp/theme"
+)
+
+// Sticky returns a [vxfw.Widget] which draws child as a sticky header. In a [Scroll], or a
+// [LazyColumn] with Headers set, a sticky header which has scrolled above the view is drawn at the
+// top of the view instead, until the next sticky header pushes it up and out of the way, so the
+// header of the section in view is always visible.
+//
+// A sticky header takes all of the width available to it, if it's bounded, and draws child over a
+// background in the [theme.Normal] style, so a header drawn over the content scrolled beneath it
+// hides that content.
+func Sticky(child vxfw.Widget) vxfw.Widget {
+	return &sticky{child: child}
+}
+
+type sticky struct {
+	child vxfw.Widget
+}
+
+var _ vxfw.Widget = &sticky{}
+
+func (s *sticky) Draw(ctx vxfw.DrawContext) (vxfw.Surface, error) {
+	child, err := s.child.Draw(ctx)
+	if err != nil {
+		return vxfw.Surface{}, err
+	}
+	width := child.Size.Width
+	if !ctx.Max.HasUnboundedWidth() && ctx.Max.Width > width {
+		width = ctx.Max.Width
+	}
+	surface := vxfw.NewSurface(width, child.Size.Height, s)
+	surface.Fill(vaxis.Cell{
+		Character: vaxis.Character{Grapheme: " ", Width: 1},
+		Style:     theme.From(ctx).Style(theme.Normal),
+	})
+	surface.AddChild(0, 0, child)
+	return surface, nil
+}
+
+// header is a sticky header found in a surface, and where it is in the view.
+type header struct {
+	x, y    int
+	surface vxfw.Surface
+}
+
+// findHeaders appends the sticky headers in s, which is at x, y in the view, to headers. Headers
+// within headers are ignored.
+func findHeaders(s vxfw.Surface, x, y int, headers []header) []header {
+	if _, ok := s.Widget.(*sticky); ok {
+		return append(headers, header{x: x, y: y, surface: s})
+	}
+	for _, child := range s.Children {
+		headers = findHeaders(child.Surface, x+child.Origin.Col, y+child.Origin.Row, headers)
+	}
+	return headers
+}
+
+// pinHeader draws the last of headers which has scrolled above the top of view at the top of
+// view, or above it if the header after it is close enough to push it up.
+func pinHeader(view *vxfw.Surface, headers []header) {
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].y < headers[j].y })
+
+	pinned := -1
+	for i, h := range headers {
+		if h.y < 0 {
+			pinned = i
+		}
+	}
+	if pinned < 0 {
+		return
+	}
+
+	h := headers[pinned]
+	height := int(h.surface.Size.Height)
+	top := 0
+	if pinned+1 < len(headers) {
+		if next := headers[pinned+1].y - height; next < top {
+			top = next
+		}
+	}
+	if top+height <= 0 {
+		return
+	}
+	view.Children = append(view.Children, vxfw.SubSurface{
+		Origin:  vxfw.RelativePoint{Col: h.x, Row: top},
+		Surface: h.surface,
+		ZIndex:  1,
+	})
+}
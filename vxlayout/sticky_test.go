@@ -0,0 +1,111 @@
+package vxlayout
+
+import (
+	"strings"
+	"testing"
+
+	"git.sr.ht/~rockorager/vaxis"
+	"git.sr.ht/~rockorager/vaxis/vxfw"
+	"git.sr.ht/~rockorager/vaxis/vxfw/text"
+	"github.com/avidal/vxexp"
+)
+
+func TestStickyScroll(t *testing.T) {
+	var children []vxfw.Widget
+	for _, line := range []string{"A", "a1", "a2", "a3", "B", "b1", "b2"} {
+		var w vxfw.Widget = text.New(line)
+		if len(line) == 1 {
+			w = Sticky(w)
+		}
+		children = append(children, w)
+	}
+	s := NewScroll(Column(children, Options{CrossAxis: CrossAxisStart}))
+
+	tests := []struct {
+		offset int
+		want   []string
+	}{
+		{0, []string{"A ", "a1", "a2"}},
+		// A sticks to the top while its section is in view
+		{2, []string{"A ", "a3", "B "}},
+		{3, []string{"A ", "B ", "b1"}},
+		// and B pushes it out
+		{4, []string{"B ", "b1", "b2"}},
+		// Scrolling stops at the end of the content
+		{10, []string{"B ", "b1", "b2"}},
+	}
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 2, Height: 3}, Characters: vaxis.Characters}
+	for _, test := range tests {
+		s.SetOffset(test.offset)
+		surface, err := s.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := graphemes(vxexp.Flatten(surface))
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Logf("wrong rows at offset %d, got=%q, want=%q", test.offset, got, test.want)
+			t.Fail()
+		}
+	}
+	if s.ContentHeight() != 7 {
+		t.Logf("wrong content height, got=%d, want=7", s.ContentHeight())
+		t.Fail()
+	}
+}
+
+func TestStickyLazyColumn(t *testing.T) {
+	// Every tenth child is a two row header
+	built := 0
+	l := NewLazyColumn(1000, func(i int) vxfw.Widget {
+		built++
+		if i%10 == 0 {
+			return Sticky(text.New(strings.Repeat("#", i/100+1) + "\n="))
+		}
+		return text.New("x")
+	})
+	l.Headers = true
+	ctx := vxfw.DrawContext{Max: vxfw.Size{Width: 3, Height: 3}, Characters: vaxis.Characters}
+	draw := func() []string {
+		surface, err := l.Draw(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return graphemes(vxexp.Flatten(surface))
+	}
+
+	tests := []struct {
+		anchor int
+		scroll int
+		want   []string
+	}{
+		{0, 0, []string{"#  ", "=  ", "x__"}},
+		// The header of the section is built, though it's far above the view
+		{105, 0, []string{"## ", "=_ ", "x__"}},
+		// The next header pushes it up
+		{109, 0, []string{"=_ ", "## ", "=_ "}},
+		// A header partly scrolled out of view is drawn whole
+		{110, 0, []string{"## ", "=_ ", "x__"}},
+		{110, 1, []string{"## ", "=_ ", "x__"}},
+	}
+	for _, test := range tests {
+		l.ScrollTo(test.anchor)
+		draw()
+		l.ScrollBy(test.scroll)
+		got := draw()
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Logf("wrong rows at %d+%d, got=%q, want=%q", test.anchor, test.scroll, got, test.want)
+			t.Fail()
+		}
+	}
+
+	// The header is kept, so only the child scrolled into view is built
+	l.ScrollTo(105)
+	draw()
+	built = 0
+	l.ScrollBy(1)
+	draw()
+	if built != 1 {
+		t.Logf("wrong number of children built, got=%d, want=1", built)
+		t.Fail()
+	}
+}